@@ -2,15 +2,19 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"syscall"
 	"time"
 
+	"github.com/Ullaakut/nmap/v3"
 	"github.com/ZeroPvlse/razor/config"
-	"github.com/ZeroPvlse/razor/defaults"
 	"github.com/ZeroPvlse/razor/mess"
+	"github.com/ZeroPvlse/razor/report"
 )
 
 func init() {
@@ -24,6 +28,9 @@ func init() {
 	}
 }
 
+// exit code when the run got cut short by SIGINT/SIGTERM
+const exitInterrupted = 130
+
 func main() {
 	razorCfg, err := config.Load(os.Args[1])
 	if err != nil {
@@ -31,6 +38,11 @@ func main() {
 		os.Exit(3)
 	}
 
+	// ctrl-c / kill: every module stops, child processes die, we still
+	// write out whatever we got so far
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	mess.PrintAscii(mess.MainLogo)
 
 	outDir := razorCfg.Report.OutDir
@@ -41,39 +53,250 @@ func main() {
 	fmt.Printf("- Targets: %v\n", razorCfg.Scope.Targets)
 	fmt.Printf("- Include ports: %v\n", razorCfg.Scope.IncludePorts)
 	if !razorCfg.Scope.TimeWindow.Start.IsZero() {
-		fmt.Printf("- Time window (UTC): %s .. %s\n",
+		fmt.Printf("- Time window (UTC): %s .. %s %s\n",
 			razorCfg.Scope.TimeWindow.Start.UTC().Format(time.RFC3339),
-			razorCfg.Scope.TimeWindow.End.UTC().Format(time.RFC3339))
+			razorCfg.Scope.TimeWindow.End.UTC().Format(time.RFC3339),
+			razorCfg.Scope.TimeWindow.Repeat)
 	}
 	fmt.Printf("- Limits: %+v\n", razorCfg.Limits)
+	fmt.Printf("- Wordlist: %d words\n", len(razorCfg.Wordlist))
 	fmt.Printf("- Deliverables: %v\n", razorCfg.Report.Deliverables)
 	fmt.Printf("- Output dir: %s\n", outDir)
 
+	// don't even start outside the time window unless asked to wait for it
+	win := razorCfg.Window()
+	if now := time.Now(); !win.Open(now) {
+		start, _, ok := win.Next(now)
+		if !ok || !razorCfg.Scope.TimeWindow.Wait {
+			fmt.Fprintf(os.Stderr, "outside of the time window, refusing to start\n")
+			os.Exit(7)
+		}
+		fmt.Printf("[~] waiting for the time window to open at %s\n", start.UTC().Format(time.RFC3339))
+		if err := win.Wait(ctx); err != nil {
+			if ctx.Err() != nil {
+				os.Exit(exitInterrupted)
+			}
+			fmt.Fprintf(os.Stderr, "err: %v\n", err)
+			os.Exit(7)
+		}
+	}
+
+	res := &results{cfg: razorCfg, outDir: outDir}
+
 	// network scan
-	nmapRes, err := razorCfg.Nmap()
+	res.nmap, err = razorCfg.Nmap(ctx)
+	res.bailIfInterrupted(ctx)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "err: %v", err)
-		os.Exit(4)
+		res.fail(4, "err", err)
+	}
+	fmt.Println(res.nmap)
+
+	// pick the key hosts, everything deeper only touches those
+	ranked := config.RankHosts(res.nmap, nil, nil)
+	keyHosts := razorCfg.KeyHosts(ranked)
+	if razorCfg.Scope.MaxHosts > 0 {
+		razorCfg.Focus(keyHosts)
+		if len(keyHosts) == 0 {
+			fmt.Println("[!] scan found no hosts to rank, max_hosts leaves nothing for the web phases")
+		}
+		fmt.Printf("- Key hosts (%d/%d):\n", len(keyHosts), len(ranked))
+		for _, h := range keyHosts {
+			fmt.Printf("\t%s score=%d ports=%v\n", h.Host, h.Score, h.OpenPorts)
+		}
+	}
+
+	// every http(s) service nmap found becomes a web target
+	web := config.WebServices(res.nmap)
+	if err := razorCfg.AddWebTargets(web); err != nil {
+		res.fail(5, "err", err)
+	}
+	if targets, err := razorCfg.WebTargets(); err == nil {
+		fmt.Printf("- Web targets (%d):\n", len(targets))
+		for _, t := range targets {
+			fmt.Printf("\t%s\n", t)
+		}
+	}
+
+	// same IP, different Host header, different app
+	vhosts, err := razorCfg.Vhosts(ctx)
+	res.findings = append(res.findings, vhosts...)
+	res.bailIfInterrupted(ctx)
+	if err != nil {
+		res.fail(5, "err", err)
+	}
+	for _, f := range vhosts {
+		fmt.Printf("[~] vhost %s\n", f.Endpoint)
+	}
+
+	// what runs where, picks the extensions and extra words enum uses
+	res.tech, err = razorCfg.Fingerprint(ctx)
+	res.bailIfInterrupted(ctx)
+	if err != nil {
+		res.fail(5, "err", err)
+	}
+	for _, t := range res.tech {
+		fmt.Printf("[~] %s runs %s %s\n", t.URL, t.Name, t.Version)
+	}
+	if targets, err := razorCfg.WebTargets(); err == nil {
+		fmt.Println("- Stack hints:")
+		for _, t := range targets {
+			fmt.Printf("\t%s %v\n", t, razorCfg.StackHints(t))
+		}
+	}
+
+	// robots.txt, sitemaps & security.txt tell us where to look first
+	seeded, err := razorCfg.Seed(ctx)
+	res.findings = append(res.findings, seeded...)
+	res.bailIfInterrupted(ctx)
+	if err != nil {
+		res.fail(5, "err", err)
 	}
-	fmt.Println(nmapRes)
 
 	// light web enum
-	webEnumRes, err := razorCfg.Enum(context.Background(), defaults.CommonEndpoints)
+	res.enum, err = razorCfg.Enum(ctx, razorCfg.Wordlist)
+	res.bailIfInterrupted(ctx)
+	if err != nil {
+		res.fail(5, "err", err)
+	}
+	fmt.Println(res.enum)
+
+	var failed []config.DirEnumRes
+	for _, r := range res.enum {
+		if r.Failed() {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Printf("[!] %d enum requests failed:\n", len(failed))
+		for _, r := range failed {
+			fmt.Printf("\t%s: %s\n", r.Endpoint, r.Error)
+		}
+	}
+
+	// beyond GET: OPTIONS, TRACE, verb tampering (PUT/DELETE only if intrusive)
+	verbs, err := razorCfg.Methods(ctx, res.enum)
+	res.findings = append(res.findings, verbs...)
+	res.bailIfInterrupted(ctx)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "err: %v", err)
-		os.Exit(5)
+		res.fail(5, "err", err)
+	}
+	for _, f := range verbs {
+		fmt.Printf("[!] %s: %s (%s)\n", f.Severity, f.Title, f.Endpoint)
+	}
+
+	// .git/.svn/.hg hits: verify them, pull the repo if intrusive
+	repos, err := razorCfg.Repos(ctx, res.enum, filepath.Join(outDir, "repos"))
+	res.findings = append(res.findings, repos...)
+	res.bailIfInterrupted(ctx)
+	if err != nil {
+		res.fail(5, "err", err)
+	}
+	for _, f := range repos {
+		fmt.Printf("[!] %s: %s (%s)\n", f.Severity, f.Title, f.Endpoint)
 	}
-	fmt.Println(webEnumRes)
 
 	// intrusive web vulns (XSS/SQLi) — only if allowed
 	if razorCfg.Scope.AllowIntrusive {
 		if err := ensureTools("xsstrike", "sqlmap"); err != nil {
-			fmt.Fprintf(os.Stderr, "tooling error: %v\n", err)
-			os.Exit(6)
+			res.fail(6, "tooling error", err)
 		}
-		razorCfg.XssScan()
-		razorCfg.SQLiScan()
+		_ = razorCfg.XssScan(ctx)
+		res.bailIfInterrupted(ctx)
+		_ = razorCfg.SQLiScan(ctx)
+		res.bailIfInterrupted(ctx)
+	}
+
+	if err := res.flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "report error: %v\n", err)
+		os.Exit(8)
+	}
+}
+
+// results is whatever the run got so far, flushed at the end or on ctrl-c
+type results struct {
+	cfg    *config.Razor
+	outDir string
+
+	nmap     *nmap.Run
+	tech     []config.Tech
+	enum     []config.DirEnumRes
+	findings []config.Finding
+}
+
+// fail writes partial results and exits with code, so an error halfway
+// through doesn't throw away what the earlier modules found. a time window
+// that closed for good exits like one that was never open.
+func (res *results) fail(code int, what string, err error) {
+	if errors.Is(err, config.ErrOutsideWindow) {
+		what, code = "time window closed", 7
+	}
+	fmt.Fprintf(os.Stderr, "%s: %v\n", what, err)
+	fmt.Println("[!] writing partial results")
+	if err := res.flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "report error: %v\n", err)
+	}
+	os.Exit(code)
+}
+
+// bailIfInterrupted writes partial results and exits if ctx got cancelled
+func (res *results) bailIfInterrupted(ctx context.Context) {
+	if ctx.Err() == nil {
+		return
+	}
+	fmt.Println("\n[!] interrupted, writing partial results")
+	if err := res.flush(); err != nil {
+		fmt.Fprintf(os.Stderr, "report error: %v\n", err)
+	}
+	os.Exit(exitInterrupted)
+}
+
+func (res *results) flush() error {
+	razorCfg := res.cfg
+
+	run := &report.Run{
+		Name:      razorCfg.Name,
+		Client:    razorCfg.Client,
+		Generated: time.Now(),
+		Tech:      res.tech,
+		Endpoints: res.enum,
+		Findings:  res.findings,
+	}
+
+	// rank again now that we know about endpoints too
+	run.Hosts = config.RankHosts(res.nmap, res.enum, run.Findings)
+	run.KeyHosts = razorCfg.KeyHosts(run.Hosts)
+
+	if guard, err := razorCfg.Guard(); err == nil {
+		run.Blocked = guard.Blocked()
+		if len(run.Blocked) > 0 {
+			fmt.Printf("[!] %d out-of-scope requests were blocked:\n", len(run.Blocked))
+			for _, u := range run.Blocked {
+				fmt.Printf("\t%s\n", u)
+			}
+		}
+	}
+
+	if budget, err := razorCfg.Budget(); err == nil {
+		run.Budget = budget.Usage()
+		fmt.Println("- Request budget per host:")
+		for _, u := range run.Budget {
+			fmt.Printf("\t%s used=%d estimated=%d limit=%d\n", u.Host, u.Used, u.Estimated, u.Limit)
+		}
+	}
+
+	razorCfg.Close()
+	run.Events = razorCfg.Events()
+	for _, e := range run.Events {
+		if e.Kind == config.EventIncident {
+			fmt.Printf("[!] INCIDENT %s %s: %s\n", e.Time.UTC().Format(time.RFC3339), e.Host, e.Detail)
+		}
+	}
+
+	written, err := report.Write(res.outDir, razorCfg.Report.Deliverables, run)
+	for _, path := range written {
+		fmt.Printf("Wrote %s\n", path)
 	}
+	return err
 }
 
 func sanitize(s string) string {
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrBudgetExhausted means a host used up Limits.TotalRequestsPerHost
+var ErrBudgetExhausted = errors.New("request budget exhausted")
+
+// rough request counts for external tools, we can't meter them from here
+const (
+	xsstrikeEstimate = 150
+	sqlmapEstimate   = 400
+)
+
+// BudgetUsage is how much of its budget a host went through
+type BudgetUsage struct {
+	Host      string `json:"host"`
+	Used      int    `json:"used"`      // requests we sent ourselves
+	Estimated int    `json:"estimated"` // reserved for external tools
+	Limit     int    `json:"limit"`     // 0 = unlimited
+}
+
+// Budget is the per-host request ledger every module draws from
+type Budget struct {
+	limit int // 0 = unlimited
+
+	mu        sync.Mutex
+	used      map[string]int
+	estimated map[string]int
+}
+
+func NewBudget(limit int) *Budget {
+	return &Budget{
+		limit:     limit,
+		used:      map[string]int{},
+		estimated: map[string]int{},
+	}
+}
+
+func (b *Budget) spent(host string) int {
+	return b.used[host] + b.estimated[host]
+}
+
+// Take draws n requests for host, all or nothing
+func (b *Budget) Take(host string, n int) error {
+	host = strings.ToLower(host)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limit > 0 && b.spent(host)+n > b.limit {
+		return fmt.Errorf("%w for %s (%d/%d)", ErrBudgetExhausted, host, b.spent(host), b.limit)
+	}
+	b.used[host] += n
+	return nil
+}
+
+// Reserve books an estimated n requests for an external tool run against host
+func (b *Budget) Reserve(host string, n int) error {
+	host = strings.ToLower(host)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.limit > 0 && b.spent(host)+n > b.limit {
+		return fmt.Errorf("%w for %s: need ~%d, %d left", ErrBudgetExhausted, host, n, b.limit-b.spent(host))
+	}
+	b.estimated[host] += n
+	return nil
+}
+
+// Remaining is how many requests host has left (-1 = unlimited)
+func (b *Budget) Remaining(host string) int {
+	if b.limit <= 0 {
+		return -1
+	}
+	host = strings.ToLower(host)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if left := b.limit - b.spent(host); left > 0 {
+		return left
+	}
+	return 0
+}
+
+// Usage lists every host that spent anything, sorted by host
+func (b *Budget) Usage() []BudgetUsage {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	hosts := map[string]struct{}{}
+	for h := range b.used {
+		hosts[h] = struct{}{}
+	}
+	for h := range b.estimated {
+		hosts[h] = struct{}{}
+	}
+
+	usage := make([]BudgetUsage, 0, len(hosts))
+	for h := range hosts {
+		usage = append(usage, BudgetUsage{
+			Host:      h,
+			Used:      b.used[h],
+			Estimated: b.estimated[h],
+			Limit:     b.limit,
+		})
+	}
+	sort.Slice(usage, func(i, j int) bool { return usage[i].Host < usage[j].Host })
+	return usage
+}
+
+// budgetDoer charges every request to its host
+type budgetDoer struct {
+	budget *Budget
+	next   httpDoer
+}
+
+func (d *budgetDoer) Do(req *http.Request) (*http.Response, error) {
+	if err := d.budget.Take(req.URL.Hostname(), 1); err != nil {
+		return nil, err
+	}
+	return d.next.Do(req)
+}
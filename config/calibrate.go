@@ -0,0 +1,151 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// biggest body we bother reading for fingerprinting
+const maxBody = 1 << 20
+
+var titleRe = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// notFound is what a host answers for a path that surely doesn't exist
+type notFound struct {
+	status   int
+	length   int
+	hash     string
+	title    string
+	location string
+}
+
+// calibration is every "not found" flavour a base URL showed us. apps that
+// answer 200 for everything (soft-404s, SPAs, wildcards) get filtered on it.
+type calibration []notFound
+
+// calibrate asks base for a few random paths and fingerprints the answers
+func calibrate(ctx context.Context, client httpDoer, base string) calibration {
+	var c calibration
+	for _, shape := range []string{"%s", "%s.php", "%s/"} {
+		token := randomToken()
+		p := strings.Replace(shape, "%s", token, 1)
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, JoinURL(base, p), nil)
+		if err != nil {
+			continue
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			continue
+		}
+		body := readBody(resp)
+
+		c = append(c, fingerprint(resp, body, token))
+	}
+	return c
+}
+
+// matches reports whether resp (for path) looks like one of the not-founds
+func (c calibration) matches(resp *http.Response, body []byte, path string) bool {
+	return c.matchesReflected(resp, body, lastSegment(path))
+}
+
+// matchesReflected is matches for when something else than the last path
+// segment gets echoed back (a Host header, say)
+func (c calibration) matchesReflected(resp *http.Response, body []byte, reflected string) bool {
+	fp := fingerprint(resp, body, reflected)
+
+	for _, nf := range c {
+		if nf.status != fp.status {
+			continue
+		}
+		if fp.status >= 300 && fp.status < 400 {
+			if nf.location == fp.location {
+				return true
+			}
+			continue
+		}
+		if nf.hash == fp.hash {
+			return true
+		}
+
+		// dynamic bits (timestamps, csrf tokens) change the hash, not the size
+		diff := nf.length - fp.length
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff <= max(32, nf.length/50) {
+			return true
+		}
+		if nf.title != "" && nf.title == fp.title && diff <= nf.length/10 {
+			return true
+		}
+	}
+	return false
+}
+
+// knowsTitle reports whether any of the not-founds had this page title
+func (c calibration) knowsTitle(title string) bool {
+	for _, nf := range c {
+		if nf.title == title {
+			return true
+		}
+	}
+	return false
+}
+
+// fingerprint strips whatever part of the path got reflected back, so
+// "/foo not found" and "/bar not found" hash the same
+func fingerprint(resp *http.Response, body []byte, reflected string) notFound {
+	if reflected != "" {
+		body = bytes.ReplaceAll(body, []byte(reflected), nil)
+	}
+	return notFound{
+		status:   resp.StatusCode,
+		length:   len(body),
+		hash:     bodyHash(body),
+		title:    pageTitle(body),
+		location: strings.ReplaceAll(resp.Header.Get("Location"), reflected, ""),
+	}
+}
+
+func readBody(resp *http.Response) []byte {
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxBody))
+	return body
+}
+
+func bodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func pageTitle(body []byte) string {
+	m := titleRe.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimSpace(html.UnescapeString(string(m[1])))
+}
+
+func lastSegment(p string) string {
+	p = strings.TrimSuffix(p, "/")
+	if i := strings.LastIndex(p, "/"); i >= 0 {
+		p = p[i+1:]
+	}
+	return p
+}
+
+func randomToken() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return "razor" + hex.EncodeToString(b)
+}
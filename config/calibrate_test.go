@@ -0,0 +1,35 @@
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestEnum_FiltersSoft404(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/admin" {
+			fmt.Fprint(w, "<html><title>Admin</title><form>login to the admin panel with your very own credentials</form></html>")
+			return
+		}
+		// every other path "exists" and reflects itself back
+		fmt.Fprintf(w, "<html><title>Oops</title>sorry, %s wasn't found</html>", r.URL.Path)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.HTTP = srv.Client()
+
+	out, err := rz.Enum(context.Background(), []string{"admin", "login", "backup.zip", "api/"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(out) != 1 || out[0].Endpoint != srv.URL+"/admin" {
+		t.Fatalf("got %#v", out)
+	}
+}
@@ -0,0 +1,269 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrHostDegraded means the canary gave up on a host, nothing else goes out
+var ErrHostDegraded = errors.New("host degraded, activity aborted")
+
+const (
+	canaryWindow     = 5 // probes we judge the error rate on
+	canaryMinSamples = 3 // don't judge on fewer than that
+	canaryPoll       = 500 * time.Millisecond
+)
+
+type HealthState int
+
+const (
+	Healthy HealthState = iota
+	Paused
+	Aborted
+)
+
+// Canary probes every host we talk to with a benign GET / while modules
+// run. when latency or error rate go over the Limits thresholds the host is
+// paused (or aborted, see Limits.CanaryAction) and it's logged as incident.
+// probes only go out while the time window is open and are charged to the
+// host's budget like everything else.
+type Canary struct {
+	limits Limits
+	probe  httpDoer // raw client, canary must not queue behind rate limits
+	guard  *ScopeGuard
+	win    *Window
+	budget *Budget
+	log    *EventLog
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu    sync.Mutex
+	hosts map[string]*hostHealth
+}
+
+type hostHealth struct {
+	state   HealthState
+	samples []bool // true = bad probe, newest last
+}
+
+func newCanary(l Limits, probe httpDoer, guard *ScopeGuard, win *Window, budget *Budget, log *EventLog) *Canary {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Canary{
+		limits: l,
+		probe:  probe,
+		guard:  guard,
+		win:    win,
+		budget: budget,
+		log:    log,
+		ctx:    ctx,
+		cancel: cancel,
+		hosts:  map[string]*hostHealth{},
+	}
+}
+
+func (c *Canary) enabled() bool {
+	return c.limits.CanaryIntervalS > 0
+}
+
+// Watch starts probing the host behind rawURL, once per host
+func (c *Canary) Watch(rawURL string) {
+	if !c.enabled() {
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Hostname() == "" {
+		return
+	}
+	host := strings.ToLower(u.Hostname())
+
+	c.mu.Lock()
+	if _, ok := c.hosts[host]; ok {
+		c.mu.Unlock()
+		return
+	}
+	c.hosts[host] = &hostHealth{}
+	c.mu.Unlock()
+
+	root := fmt.Sprintf("%s://%s/", u.Scheme, u.Host)
+	go c.run(host, root)
+}
+
+func (c *Canary) State(host string) HealthState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if h, ok := c.hosts[strings.ToLower(host)]; ok {
+		return h.state
+	}
+	return Healthy
+}
+
+// Wait blocks while host is paused, errors out once it's aborted
+func (c *Canary) Wait(ctx context.Context, host string) error {
+	for {
+		switch c.State(host) {
+		case Healthy:
+			return nil
+		case Aborted:
+			return fmt.Errorf("%w: %s", ErrHostDegraded, host)
+		}
+
+		timer := time.NewTimer(canaryPoll)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Stop ends every probe loop
+func (c *Canary) Stop() {
+	c.cancel()
+}
+
+func (c *Canary) run(host, root string) {
+	ticker := time.NewTicker(time.Duration(c.limits.CanaryIntervalS) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		c.check(host, root)
+		if c.State(host) == Aborted {
+			return
+		}
+
+		select {
+		case <-c.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Canary) check(host, root string) {
+	req, err := http.NewRequestWithContext(c.ctx, http.MethodGet, root, nil)
+	if err != nil {
+		return
+	}
+	if c.guard.PathExcluded(req.URL.Path) {
+		return // "/" itself is off limits, nothing benign left to probe
+	}
+	if !c.win.Open(time.Now()) {
+		return // window closed, the host gets no traffic at all
+	}
+	if err := c.budget.Take(host, 1); err != nil {
+		return // out of budget, a probe is a request like any other
+	}
+
+	start := time.Now()
+	resp, err := c.probe.Do(req)
+	latency := time.Since(start)
+	if c.ctx.Err() != nil {
+		return
+	}
+
+	var problem string
+	switch {
+	case err != nil:
+		problem = err.Error()
+	case resp.StatusCode >= 500:
+		problem = resp.Status
+	case latency > time.Duration(c.limits.CanaryMaxLatencyMs)*time.Millisecond:
+		problem = fmt.Sprintf("latency %v", latency.Round(time.Millisecond))
+	}
+	if resp != nil {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+	}
+
+	c.record(host, problem)
+}
+
+// record adds a probe result and moves host between states
+func (c *Canary) record(host, problem string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	h := c.hosts[host]
+	h.samples = append(h.samples, problem != "")
+	if len(h.samples) > canaryWindow {
+		h.samples = h.samples[1:]
+	}
+	if len(h.samples) < canaryMinSamples || h.state == Aborted {
+		return
+	}
+
+	bad := 0
+	for _, b := range h.samples {
+		if b {
+			bad++
+		}
+	}
+	rate := float64(bad) / float64(len(h.samples))
+
+	switch {
+	case h.state == Healthy && rate > c.limits.CanaryMaxErrorRate:
+		if c.limits.CanaryAction == "abort" {
+			h.state = Aborted
+			c.log.Add(host, EventIncident, "host degraded (%d/%d bad probes, last: %s), aborting everything against it", bad, len(h.samples), problem)
+		} else {
+			h.state = Paused
+			c.log.Add(host, EventIncident, "host degraded (%d/%d bad probes, last: %s), pausing until it recovers", bad, len(h.samples), problem)
+		}
+	case h.state == Paused && bad == 0:
+		h.state = Healthy
+		c.log.Add(host, EventRecover, "host healthy again, resuming")
+	}
+}
+
+// supervise pauses/kills an external tool along with its host's health
+func (c *Canary) supervise(done <-chan struct{}, ps *pauser, host string) {
+	if !c.enabled() {
+		return
+	}
+
+	const reason = "host degraded"
+	ticker := time.NewTicker(canaryPoll)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+		}
+
+		switch c.State(host) {
+		case Paused:
+			ps.pause(reason)
+		case Aborted:
+			ps.kill(reason)
+			return
+		default:
+			ps.resume(reason)
+		}
+	}
+}
+
+// healthDoer starts the canary for every host it sees and holds requests
+// while that host is unwell
+type healthDoer struct {
+	canary *Canary
+	next   httpDoer
+}
+
+func (d *healthDoer) Do(req *http.Request) (*http.Response, error) {
+	d.canary.Watch(req.URL.String())
+	if err := d.canary.Wait(req.Context(), req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	return d.next.Do(req)
+}
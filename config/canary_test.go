@@ -0,0 +1,92 @@
+package config_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestCanary_AbortsDegradedHost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			w.WriteHeader(http.StatusBadGateway) // canary sees a dying host
+			return
+		}
+		if len(r.URL.Path) != 2 { // calibration
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Limits.RPSPerHost = 4
+	rz.Limits.CanaryIntervalS = 1
+	rz.Limits.CanaryMaxErrorRate = 0.5
+	rz.Limits.CanaryAction = "abort"
+	rz.HTTP = srv.Client()
+	defer rz.Close()
+
+	var words []string
+	for c := 'a'; c <= 't'; c++ {
+		words = append(words, string(c))
+	}
+
+	out, err := rz.Enum(context.Background(), words)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(out) == 0 || len(out) >= len(words) {
+		t.Fatalf("wanted enum cut short, got %#v", out)
+	}
+
+	var incidents int
+	for _, e := range rz.Events() {
+		if e.Kind == config.EventIncident {
+			incidents++
+		}
+	}
+	if incidents != 1 {
+		t.Fatalf("got events %+v", rz.Events())
+	}
+}
+
+func TestCanary_ChargesBudget(t *testing.T) {
+	var hits, probes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		if r.URL.Path == "/" {
+			probes.Add(1)
+		}
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Limits.CanaryIntervalS = 1
+	rz.Limits.CanaryMaxErrorRate = 0.5
+	rz.HTTP = srv.Client()
+
+	if _, err := rz.Enum(context.Background(), []string{"a"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	time.Sleep(1500 * time.Millisecond) // a couple of probes
+	rz.Close()
+
+	budget, _ := rz.Budget()
+	var used int
+	for _, u := range budget.Usage() {
+		used += u.Used
+	}
+	if probes.Load() == 0 || used < int(hits.Load()) {
+		t.Fatalf("%d requests (%d probes) but only %d charged", hits.Load(), probes.Load(), used)
+	}
+}
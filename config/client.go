@@ -0,0 +1,48 @@
+package config
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// NewHTTPClient builds the client every native module talks through:
+// connect/request timeouts from Limits, keep-alive pool sized to
+// Concurrency and no automatic redirects (we look at every hop ourselves).
+// zero limits mean no timeout.
+func NewHTTPClient(l Limits) *http.Client {
+	connect := time.Duration(l.ConnectTimeoutS) * time.Second
+	request := time.Duration(l.RequestTimeoutS) * time.Second
+
+	pool := l.Concurrency
+	if pool <= 0 {
+		pool = 1
+	}
+
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   connect,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   connect,
+		ResponseHeaderTimeout: request,
+		MaxIdleConns:          pool * 4,
+		MaxIdleConnsPerHost:   pool,
+		MaxConnsPerHost:       pool,
+		IdleConnTimeout:       90 * time.Second,
+		ForceAttemptHTTP2:     true,
+		// we're testing the target, not trusting it. self-signed certs are
+		// everywhere on client networks
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   request,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
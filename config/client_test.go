@@ -0,0 +1,36 @@
+package config_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestNewHTTPClient(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(1500 * time.Millisecond)
+			return
+		}
+		http.Redirect(w, r, "/elsewhere", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	client := config.NewHTTPClient(config.Limits{ConnectTimeoutS: 1, RequestTimeoutS: 1, Concurrency: 2})
+
+	resp, err := client.Get(srv.URL + "/redir")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound || resp.Header.Get("Location") != "/elsewhere" {
+		t.Fatalf("redirect was followed: %d", resp.StatusCode)
+	}
+
+	if _, err := client.Get(srv.URL + "/slow"); err == nil {
+		t.Fatalf("wanted a timeout")
+	}
+}
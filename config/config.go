@@ -2,13 +2,19 @@
 package config
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/Ullaakut/nmap/v3"
@@ -38,13 +44,26 @@ func (t *ISOTime) UnmarshalYAML(node *yaml.Node) error {
 func (t ISOTime) IsZero() bool { return t.Time.IsZero() }
 
 type Razor struct {
-	Name   string `yaml:"name"`
-	Client string `yaml:"client"`
-	Scope  Scope  `yaml:"scope"`
-	Limits Limits `yaml:"limits"`
-	Report Report `yaml:"report"`
-	Notes  Notes  `yaml:"notes"`
-	HTTP   httpDoer
+	Name        string      `yaml:"name"`
+	Client      string      `yaml:"client"`
+	Scope       Scope       `yaml:"scope"`
+	Limits      Limits      `yaml:"limits"`
+	Report      Report      `yaml:"report"`
+	Notes       Notes       `yaml:"notes"`
+	Enumeration Enumeration `yaml:"enum"`
+	Wordlists   Wordlists   `yaml:"wordlists"`
+	HTTP        httpDoer    `yaml:"-"` // NewHTTPClient(Limits) unless set
+
+	Wordlist []string `yaml:"-"` // Wordlists resolved by Load()
+
+	stateOnce sync.Once
+	state     *runState
+	stateErr  error
+
+	focus map[string]struct{} // key hosts, see Focus()
+	web   []string            // base URLs found by nmap, see AddWebTargets()
+	seeds map[string][]string // extra words per base URL, see Seed() and Fingerprint()
+	hints map[string][]string // detected stack per base URL, see Fingerprint()
 }
 
 type Scope struct {
@@ -53,16 +72,18 @@ type Scope struct {
 	MaxHosts       int        `yaml:"max_hosts"`
 	AllowIntrusive bool       `yaml:"allow_intrusive"`
 	TimeWindow     TimeWindow `yaml:"time_window"`
-}
+	ExcludeTargets []string   `yaml:"exclude_targets"` // carve-outs from targets (domains/IPs/CIDRs)
+	ExcludePorts   []int      `yaml:"exclude_ports"`
+	ExcludePaths   []string   `yaml:"exclude_paths"` // "/checkout" also covers everything below it
 
-type DirEnumRes struct {
-	Endpoint   string
-	StatusCode int
+	Parsed []Target `yaml:"-"` // Targets after Load(), see ParsedTargets()
 }
 
 type TimeWindow struct {
-	Start ISOTime `yaml:"start"`
-	End   ISOTime `yaml:"end"`
+	Start  ISOTime `yaml:"start"`
+	End    ISOTime `yaml:"end"`
+	Repeat string  `yaml:"repeat"` // "" = one shot, "daily" = same slot every day
+	Wait   bool    `yaml:"wait"`   // wait for the window to open instead of refusing to start
 }
 
 type Limits struct {
@@ -72,6 +93,20 @@ type Limits struct {
 	ConnectTimeoutS      int `yaml:"connect_timeout_s"`
 	RequestTimeoutS      int `yaml:"request_timeout_s"`
 	Retries              int `yaml:"retries"`
+	ScanTimeoutS         int `yaml:"scan_timeout_s"` // cap on one nmap run, 0 = none (ctx and time window still apply)
+
+	// health canary, see Canary. canary_interval_s: -1 turns it off
+	CanaryIntervalS    int     `yaml:"canary_interval_s"`
+	CanaryMaxLatencyMs int     `yaml:"canary_max_latency_ms"`
+	CanaryMaxErrorRate float64 `yaml:"canary_max_error_rate"` // 0..1 over the last few probes
+	CanaryAction       string  `yaml:"canary_action"`         // "pause" or "abort"
+}
+
+type Enumeration struct {
+	RecursionDepth    int      `yaml:"recursion_depth"`    // levels below discovered dirs, 0 = off
+	RecursionWordlist []string `yaml:"recursion_wordlist"` // empty = same list as the top level
+	Redirects         string   `yaml:"redirects"`          // "none", "same-host" or "follow", see DirEnumRes.Redirects
+	Vhosts            []string `yaml:"vhosts"`             // Host header candidates for bare IPs, see Vhosts()
 }
 
 type Report struct {
@@ -99,10 +134,18 @@ func Load(path string) (*Razor, error) {
 	if err := yaml.Unmarshal(b, &c); err != nil {
 		return nil, err
 	}
+	if c.Scope.Parsed, err = ParseTargets(c.Scope.Targets); err != nil {
+		return nil, err
+	}
 	ApplyDefaults(&c)
 	if err := c.validate(); err != nil {
 		return nil, err
 	}
+	if c.Wordlist, err = c.Wordlists.Resolve(filepath.Dir(path)); err != nil {
+		return nil, err
+	}
+	c.Enumeration.RecursionWordlist = NormalizeWords(c.Enumeration.RecursionWordlist)
+	c.HTTP = NewHTTPClient(c.Limits)
 	return &c, nil
 }
 
@@ -123,6 +166,22 @@ func ApplyDefaults(c *Razor) {
 	if c.Limits.RequestTimeoutS == 0 {
 		c.Limits.RequestTimeoutS = 10
 	}
+	if c.Limits.CanaryIntervalS == 0 {
+		c.Limits.CanaryIntervalS = 15
+	}
+	if c.Limits.CanaryMaxLatencyMs == 0 {
+		c.Limits.CanaryMaxLatencyMs = 3000
+	}
+	if c.Limits.CanaryMaxErrorRate == 0 {
+		c.Limits.CanaryMaxErrorRate = 0.5
+	}
+	if c.Limits.CanaryAction == "" {
+		c.Limits.CanaryAction = "pause"
+	}
+	// Enum
+	if c.Enumeration.Redirects == "" {
+		c.Enumeration.Redirects = RedirectNone
+	}
 	// Report
 	if c.Report.CVSS == "" {
 		c.Report.CVSS = "v3.1"
@@ -149,12 +208,28 @@ func (c *Razor) validate() error {
 	if len(c.Scope.Targets) == 0 {
 		return errors.New("target needs to be specified")
 	}
+	if c.Scope.MaxHosts < 0 {
+		return errors.New("max_hosts must be >= 0")
+	}
+	if _, err := newScopeSet(c.Scope); err != nil {
+		return err
+	}
 	// Scope
 	for _, p := range c.Scope.IncludePorts {
 		if p < 1 || p > 65535 {
 			return fmt.Errorf("include_ports contains invalid port: %d", p)
 		}
 	}
+	for _, p := range c.Scope.ExcludePorts {
+		if p < 1 || p > 65535 {
+			return fmt.Errorf("exclude_ports contains invalid port: %d", p)
+		}
+	}
+	for _, p := range c.Scope.ExcludePaths {
+		if !strings.HasPrefix(p, "/") {
+			return fmt.Errorf("exclude_paths entry %q must start with '/'", p)
+		}
+	}
 	if !c.Scope.TimeWindow.Start.IsZero() || !c.Scope.TimeWindow.End.IsZero() {
 		if c.Scope.TimeWindow.Start.IsZero() || c.Scope.TimeWindow.End.IsZero() {
 			return errors.New("time_window must have both start and end or be empty")
@@ -163,6 +238,16 @@ func (c *Razor) validate() error {
 			return errors.New("time_window.end must be after time_window.start")
 		}
 	}
+	switch c.Scope.TimeWindow.Repeat {
+	case "":
+	case "daily":
+		tw := c.Scope.TimeWindow
+		if tw.Start.IsZero() || tw.End.Sub(tw.Start.Time) >= day {
+			return errors.New("daily time_window needs start and end less than 24h apart")
+		}
+	default:
+		return fmt.Errorf("unknown time_window.repeat %q (allowed: daily)", c.Scope.TimeWindow.Repeat)
+	}
 
 	// Limits
 	if c.Limits.RPSPerHost < 0 {
@@ -177,9 +262,32 @@ func (c *Razor) validate() error {
 	if c.Limits.ConnectTimeoutS <= 0 || c.Limits.RequestTimeoutS <= 0 {
 		return errors.New("timeouts must be > 0")
 	}
+	if c.Enumeration.RecursionDepth < 0 {
+		return errors.New("enum.recursion_depth must be >= 0")
+	}
+	switch c.Enumeration.Redirects {
+	case RedirectNone, RedirectSameHost, RedirectFollow:
+	default:
+		return fmt.Errorf("unknown enum.redirects %q (allowed: none, same-host, follow)", c.Enumeration.Redirects)
+	}
 	if c.Limits.Retries < 0 {
 		return errors.New("retries must be >= 0")
 	}
+	if c.Limits.ScanTimeoutS < 0 {
+		return errors.New("scan_timeout_s must be >= 0 (0 = no cap)")
+	}
+	if c.Limits.CanaryIntervalS < -1 {
+		return errors.New("canary_interval_s must be > 0 (or -1 to turn the canary off)")
+	}
+	if c.Limits.CanaryMaxLatencyMs < 0 {
+		return errors.New("canary_max_latency_ms must be > 0")
+	}
+	if c.Limits.CanaryMaxErrorRate < 0 || c.Limits.CanaryMaxErrorRate >= 1 {
+		return errors.New("canary_max_error_rate must be between 0 and 1")
+	}
+	if c.Limits.CanaryAction != "pause" && c.Limits.CanaryAction != "abort" {
+		return fmt.Errorf("unknown canary_action %q (allowed: pause, abort)", c.Limits.CanaryAction)
+	}
 
 	// Report
 	allowed := map[string]struct{}{
@@ -196,16 +304,53 @@ func (c *Razor) validate() error {
 	return nil
 }
 
-func (cfg *Razor) Nmap() (*nmap.Run, error) {
+// errWindowClosed: the scan was cut short because the time window ended
+var errWindowClosed = errors.New("time window closed mid scan")
+
+// Nmap only runs inside the time window. nmap can't be paused from here,
+// so when the window closes we drop the scan and redo it in the next one.
+// cancelling ctx kills nmap.
+func (cfg *Razor) Nmap(ctx context.Context) (*nmap.Run, error) {
+	win := cfg.Window()
+	for {
+		if err := win.Wait(ctx); err != nil {
+			return nil, err
+		}
+
+		result, err := cfg.nmapOnce(ctx, win.Closes(time.Now()))
+		if errors.Is(err, errWindowClosed) {
+			fmt.Println("[~] time window closed, nmap will restart in the next one")
+			continue
+		}
+		return result, err
+	}
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+func (cfg *Razor) nmapOnce(parent context.Context, closes time.Time) (*nmap.Run, error) {
+	ctx, cancel := context.WithCancel(parent)
 	defer cancel()
+	timeout := time.Duration(cfg.Limits.ScanTimeoutS) * time.Second
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	if !closes.IsZero() {
+		ctx, cancel = context.WithDeadline(ctx, closes)
+		defer cancel()
+	}
 
 	ports := SlicePortToStr(cfg.Scope.IncludePorts)
 
-	scanner, err := nmap.NewScanner(
-		ctx,
-		nmap.WithTargets(cfg.Scope.Targets...),
+	hosts, err := cfg.Scope.Hosts()
+	if err != nil {
+		return nil, err
+	}
+	if len(hosts) == 0 {
+		return nil, errors.New("no scannable targets (wildcards alone can't be scanned)")
+	}
+
+	opts := []nmap.Option{
+		nmap.WithTargets(hosts...),
 		nmap.WithPorts(ports),
 		nmap.WithAggressiveScan(),  // os detection + service info + default script + trace route
 		nmap.WithFragmentPackets(), // <- we go stealthy as fuck
@@ -219,7 +364,27 @@ func (cfg *Razor) Nmap() (*nmap.Run, error) {
 
 			return false
 		}),
-	)
+	}
+	if len(cfg.Scope.ExcludeTargets) > 0 {
+		excluded, err := ParseTargets(cfg.Scope.ExcludeTargets)
+		if err != nil {
+			return nil, err
+		}
+		var ex []string
+		for _, t := range excluded {
+			if h := t.NmapTarget(); h != "" {
+				ex = append(ex, h)
+			}
+		}
+		if len(ex) > 0 { // all wildcards: nothing nmap could use
+			opts = append(opts, nmap.WithTargetExclusions(ex...))
+		}
+	}
+	if len(cfg.Scope.ExcludePorts) > 0 {
+		opts = append(opts, nmap.WithPortExclusions(SlicePortToStr(cfg.Scope.ExcludePorts)))
+	}
+
+	scanner, err := nmap.NewScanner(ctx, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("unable to create nmap scanner: %v", err)
 	}
@@ -230,6 +395,15 @@ func (cfg *Razor) Nmap() (*nmap.Run, error) {
 		// warnings are fine :3
 	}
 	if err != nil {
+		if parent.Err() != nil {
+			return nil, parent.Err()
+		}
+		if !closes.IsZero() && !time.Now().Before(closes) {
+			return nil, errWindowClosed
+		}
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("nmap scan hit scan_timeout_s (%v): %v", timeout, err)
+		}
 		return nil, fmt.Errorf("nmap scan failed: %v", err)
 	}
 
@@ -257,51 +431,263 @@ func SlicePortToStr(slicePorts []int) string {
 	return ports
 }
 
-// just worse gobuster lol
-func (cfg *Razor) Enum(ctx context.Context, wordlist []string) ([]DirEnumRes, error) {
-	var (
-		sb      strings.Builder
-		enumRes []DirEnumRes
-	)
+// runState is whatever the HTTP stack has to share between modules for
+// the whole run (blocked list, per-host rate limits, ...)
+type runState struct {
+	guard   *ScopeGuard
+	limiter *RateLimiter
+	budget  *Budget
+	log     *EventLog
+	canary  *Canary
+}
+
+func (cfg *Razor) runState() (*runState, error) {
+	cfg.stateOnce.Do(func() {
+		if cfg.HTTP == nil {
+			cfg.HTTP = NewHTTPClient(cfg.Limits)
+		}
 
-	client := cfg.HTTP
-	if client == nil {
-		client = http.DefaultClient
+		guard, err := NewScopeGuard(cfg.Scope, cfg.HTTP)
+		if err != nil {
+			cfg.stateErr = err
+			return
+		}
+		st := &runState{
+			guard:   guard,
+			limiter: NewRateLimiter(cfg.Limits.RPSPerHost),
+			budget:  NewBudget(cfg.Limits.TotalRequestsPerHost),
+			log:     &EventLog{},
+		}
+		// before wrap: no rate limits, the canary checks window and budget itself
+		st.canary = newCanary(cfg.Limits, guard.next, guard, cfg.Window(), st.budget, st.log)
+
+		// only in-scope requests get to spend budget and rate limit tokens
+		guard.wrap(func(next httpDoer) httpDoer {
+			next = &timeDoer{next: next}
+			next = &limitDoer{lim: st.limiter, next: next}
+			next = &budgetDoer{budget: st.budget, next: next}
+			next = &throttleDoer{lim: st.limiter, log: st.log, next: next} // re-sends pay too
+			next = &retryDoer{retries: cfg.Limits.Retries, next: next}
+			return &healthDoer{canary: st.canary, next: next}
+		})
+		cfg.state = st
+	})
+	return cfg.state, cfg.stateErr
+}
+
+// Guard returns cfg.HTTP (NewHTTPClient by default) wrapped in a ScopeGuard.
+// it's built once, so every module shares the same blocked list.
+func (cfg *Razor) Guard() (*ScopeGuard, error) {
+	st, err := cfg.runState()
+	if err != nil {
+		return nil, err
 	}
+	return st.guard, nil
+}
 
-	for _, target := range cfg.Scope.Targets {
-		for _, word := range wordlist {
-			sb.Reset() // important: avoid sticky appends
-			sb.WriteString(fmt.Sprintf("%s/%s", target, word))
+// client is the HTTP stack every native module goes through:
+// time window -> scope guard -> health canary -> retries -> adaptive
+// throttling -> budget -> per-host rate limit -> timing -> cfg.HTTP
+func (cfg *Razor) client() (httpDoer, error) {
+	st, err := cfg.runState()
+	if err != nil {
+		return nil, err
+	}
+	return &windowDoer{win: cfg.Window(), next: st.guard}, nil
+}
 
-			req, err := http.NewRequestWithContext(ctx, http.MethodGet, sb.String(), nil)
-			if err != nil {
-				return enumRes, err
-			}
+// Close stops whatever keeps running in the background (canary probes)
+func (cfg *Razor) Close() {
+	if st, err := cfg.runState(); err == nil {
+		st.canary.Stop()
+	}
+}
 
-			r, err := client.Do(req)
-			if err != nil {
-				return enumRes, err
-			}
-			_ = r.Body.Close()
+// Events returns the run log (back-offs, incidents, ...)
+func (cfg *Razor) Events() []Event {
+	st, err := cfg.runState()
+	if err != nil {
+		return nil
+	}
+	return st.log.Events()
+}
 
-			if r.StatusCode >= 200 && r.StatusCode <= 400 {
-				enumRes = append(enumRes, DirEnumRes{
-					Endpoint:   req.URL.String(),
-					StatusCode: r.StatusCode,
-				})
-			}
+// Budget returns the per-host request ledger shared by every module
+func (cfg *Razor) Budget() (*Budget, error) {
+	st, err := cfg.runState()
+	if err != nil {
+		return nil, err
+	}
+	return st.budget, nil
+}
+
+// runTool runs an external tool against target inside the time window,
+// pausing it (SIGSTOP/SIGCONT) whenever the window closes or the canary
+// says the host is struggling. cancelling ctx kills it.
+func (cfg *Razor) runTool(ctx context.Context, target, name string, args ...string) ([]byte, error) {
+	st, err := cfg.runState()
+	if err != nil {
+		return nil, err
+	}
+	t, err := ParseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+	// the canary probes with the raw client, no vhost routing: hand it the IP
+	host := t.Host
+	if urls := t.BaseURLs(); len(urls) > 0 {
+		if u, err := url.Parse(urls[0]); err == nil {
+			u = st.guard.onIP(u)
+			host = u.Hostname()
+			st.canary.Watch(u.String())
 		}
 	}
 
-	return enumRes, nil
+	win := cfg.Window()
+	if err := win.Wait(ctx); err != nil {
+		return nil, err
+	}
+	if err := st.canary.Wait(ctx, host); err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	ps := newPauser(cmd.Process, name)
+	done := make(chan struct{})
+	go win.supervise(done, ps)
+	go st.canary.supervise(done, ps, host)
+	err = cmd.Wait()
+	close(done)
+
+	return out.Bytes(), err
 }
 
-func (cfg *Razor) XssScan() {
-	for _, target := range cfg.Scope.Targets {
-		cmd := exec.Command("xsstrike", "-u", target)
+// toolTargets are the targets handed to external tools: one URL per web
+// service (scheme, host and port), the ones nmap found first, then the raw
+// host targets nmap didn't turn up anything for. each run reserves a chunk
+// of the host's budget, so "example.com", "http://example.com/" and
+// "http://example.com:80/" must not get a run each. key hosts cut and scope
+// guard apply. CIDRs and wildcards only get there through nmap, vhosts
+// from Vhosts() don't get there at all (their names don't resolve).
+func (cfg *Razor) toolTargets() []string {
+	guard, err := cfg.Guard()
+	if err != nil {
+		return nil
+	}
 
-		out, err := cmd.CombinedOutput()
+	services := map[string]bool{} // scheme://host:port
+	hosts := map[string]bool{}    // hosts with at least one service
+	var targets []string
+	for _, raw := range append(append([]string(nil), cfg.web...), cfg.Scope.Targets...) {
+		t, err := ParseTarget(raw)
+		if err != nil || t.Kind != TargetHost || !cfg.inFocus(t.Host) {
+			continue
+		}
+		if _, ok := guard.vhostIP(t.Host); ok {
+			continue // only resolves through the guard, tools would ask public DNS
+		}
+		if t.Scheme == "" && hosts[t.Host] {
+			continue // bare host, we already know where its web services are
+		}
+		base := toolURL(guard, t)
+		if base == "" {
+			continue
+		}
+		u, err := url.Parse(base)
+		if err != nil {
+			continue
+		}
+		key := fmt.Sprintf("%s://%s:%d", u.Scheme, t.Host, urlPort(u))
+		if services[key] {
+			continue
+		}
+		services[key], hosts[t.Host] = true, true
+		targets = append(targets, base)
+	}
+	return targets
+}
+
+// toolURL is the first of t's web roots that's in scope, path included
+// ("" = none)
+func toolURL(guard *ScopeGuard, t Target) string {
+	for _, base := range t.BaseURLs() {
+		u, err := url.Parse(base)
+		if err != nil || guard.PathExcluded(u.Path) {
+			continue
+		}
+		if ok, _ := guard.allows(u); ok {
+			return base
+		}
+	}
+	return ""
+}
+
+// crawlExclude is a --crawl-exclude regex for sqlmap covering
+// Scope.ExcludePaths ("" = nothing excluded)
+func (cfg *Razor) crawlExclude() string {
+	var alts []string
+	for _, p := range cfg.Scope.ExcludePaths {
+		if p = strings.TrimSuffix(normalizePath(p), "/"); p != "" {
+			alts = append(alts, regexp.QuoteMeta(p))
+		}
+	}
+	if len(alts) == 0 {
+		return ""
+	}
+	// "/checkout" covers "/checkout/..." and "/checkout?x" too, not "/checkouts"
+	return `^[a-z]+://[^/]+(?:` + strings.Join(alts, "|") + `)(?:[/?#]|$)`
+}
+
+// toolDelay is the delay (seconds) between requests that external tools
+// need to stay under Limits.RPSPerHost. 0 = no limit.
+func (cfg *Razor) toolDelay() float64 {
+	if cfg.Limits.RPSPerHost <= 0 {
+		return 0
+	}
+	return 1 / float64(cfg.Limits.RPSPerHost)
+}
+
+// reserveTool books an external tool's estimated requests against target's
+// host. false = not enough budget left, skip the tool there.
+func (cfg *Razor) reserveTool(name, target string, estimate int) bool {
+	t, err := ParseTarget(target)
+	if err != nil {
+		return false
+	}
+	budget, err := cfg.Budget()
+	if err != nil {
+		return false
+	}
+	if err := budget.Reserve(t.Host, estimate); err != nil {
+		fmt.Printf("[!] skipping %s on %s: %v\n", name, target, err)
+		return false
+	}
+	return true
+}
+
+// XssScan runs xsstrike on every key target. only returns ctx's error,
+// tool failures are printed and we move on.
+func (cfg *Razor) XssScan(ctx context.Context) error {
+	for _, target := range cfg.toolTargets() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !cfg.reserveTool("xsstrike", target, xsstrikeEstimate) {
+			continue
+		}
+		args := []string{"-u", target}
+		if d := cfg.toolDelay(); d > 0 {
+			args = append(args, "-d", fmt.Sprintf("%d", int(math.Ceil(d)))) // xsstrike only takes whole seconds
+		}
+
+		out, err := cfg.runTool(ctx, target, "xsstrike", args...)
 		if err != nil {
 			fmt.Printf("[!] Error running xsstrike on %s: %v\n", target, err)
 		}
@@ -309,10 +695,18 @@ func (cfg *Razor) XssScan() {
 		fmt.Printf("=== Result for %s ===\n%s\n", target, string(out))
 	}
 
+	return ctx.Err()
 }
 
-func (cfg *Razor) SQLiScan() {
-	for _, target := range cfg.Scope.Targets {
+// SQLiScan is XssScan with sqlmap
+func (cfg *Razor) SQLiScan(ctx context.Context) error {
+	for _, target := range cfg.toolTargets() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !cfg.reserveTool("sqlmap", target, sqlmapEstimate) {
+			continue
+		}
 		args := []string{
 			"-u", target,
 			"--batch",
@@ -324,13 +718,20 @@ func (cfg *Razor) SQLiScan() {
 			"--technique=BEUSTQ",
 			"--tamper=between",
 		}
+		if ex := cfg.crawlExclude(); ex != "" {
+			args = append(args, "--crawl-exclude="+ex)
+		}
+		if d := cfg.toolDelay(); d > 0 {
+			args = append(args, "--threads=1", fmt.Sprintf("--delay=%.2f", d))
+		}
 
-		cmd := exec.Command("sqlmap", args...)
-		out, err := cmd.CombinedOutput()
+		out, err := cfg.runTool(ctx, target, "sqlmap", args...)
 		if err != nil {
 			fmt.Printf("error running sqlmap on %s: %v\n", target, err)
 		}
 
 		fmt.Printf("=== SQLi result for %s ===\n%s\n", target, string(out))
 	}
+
+	return ctx.Err()
 }
@@ -2,8 +2,12 @@ package config_test
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/ZeroPvlse/razor/config"
@@ -62,3 +66,358 @@ func TestEnum_WithServer(t *testing.T) {
 		t.Fatalf("got %#v", out)
 	}
 }
+
+func TestEnum_StopsOnBudget(t *testing.T) {
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if len(r.URL.Path) != 2 { // calibration
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Limits.TotalRequestsPerHost = 5 // 3 go to calibration
+	rz.HTTP = srv.Client()
+
+	out, err := rz.Enum(context.Background(), []string{"a", "b", "c", "d"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if hits != 5 || len(out) != 2 {
+		t.Fatalf("got %d hits, %#v", hits, out)
+	}
+
+	budget, _ := rz.Budget()
+	if u := budget.Usage(); len(u) != 1 || u[0].Used != 5 {
+		t.Fatalf("got usage %+v", u)
+	}
+}
+
+func TestEnum_ConcurrentSorted(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Path) != 2 { // calibration
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Limits.Concurrency = 4
+	rz.HTTP = srv.Client()
+
+	out, err := rz.Enum(context.Background(), []string{"d", "b", "c", "a", "e"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(out) != 5 {
+		t.Fatalf("got %#v", out)
+	}
+	for i, want := range []string{"a", "b", "c", "d", "e"} {
+		if out[i].Endpoint != srv.URL+"/"+want {
+			t.Errorf("%d: got %s", i, out[i].Endpoint)
+		}
+	}
+}
+
+func TestEnum_RetriesAndKeepsGoing(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		attempts[r.URL.Path]++
+		n := attempts[r.URL.Path]
+		mu.Unlock()
+
+		// /flaky drops the first connection, /dead drops all of them
+		if r.URL.Path == "/dead" || (r.URL.Path == "/flaky" && n == 1) {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		if r.URL.Path != "/flaky" && r.URL.Path != "/ok" { // calibration
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Limits.Retries = 1
+	rz.HTTP = srv.Client()
+
+	out, err := rz.Enum(context.Background(), []string{"dead", "flaky", "ok"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(out) != 3 || !out[0].Failed() || out[1].Failed() || out[2].StatusCode != 200 {
+		t.Fatalf("got %#v", out)
+	}
+	// net/http itself may resend once on a reused connection, so >= 2
+	if attempts["/dead"] < 2 || attempts["/flaky"] != 2 {
+		t.Fatalf("got attempts %v", attempts)
+	}
+}
+
+func TestEnum_ResponseMetadata(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/login" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Server", "nginx/1.18.0")
+		w.Header().Set("X-Powered-By", "PHP/7.4.3")
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("Location", "/sso")
+		w.WriteHeader(http.StatusFound)
+		w.Write([]byte("<title>Sign in</title>"))
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.HTTP = config.NewHTTPClient(config.Limits{})
+
+	out, err := rz.Enum(context.Background(), []string{"login"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(out) != 1 {
+		t.Fatalf("got %#v", out)
+	}
+	got := out[0]
+	if got.StatusCode != 302 || got.Location != "/sso" || got.Title != "Sign in" ||
+		got.ContentType != "text/html" || got.ContentLength != 22 || got.BodyHash == "" ||
+		got.Headers["Server"] != "nginx/1.18.0" || got.Headers["X-Powered-By"] != "PHP/7.4.3" ||
+		got.ResponseTime <= 0 {
+		t.Fatalf("got %#v", got)
+	}
+}
+
+func TestEnum_Recursion(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/", "/api/v1/", "/api/v1/users", "/api/v1/v1/":
+			fmt.Fprint(w, r.URL.Path)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Enumeration.RecursionDepth = 1
+	rz.Enumeration.RecursionWordlist = []string{"v1/", "users"}
+	rz.HTTP = srv.Client()
+
+	out, err := rz.Enum(context.Background(), []string{"api/", "v1/"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	// /api/v1/users and /api/v1/v1/ are 2 levels deep, out of reach
+	if len(out) != 2 || out[0].Endpoint != srv.URL+"/api/" || out[1].Endpoint != srv.URL+"/api/v1/" {
+		t.Fatalf("got %#v", out)
+	}
+}
+
+func TestEnum_Permutations(t *testing.T) {
+	var hits sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Store(r.URL.Path, true)
+		switch r.URL.Path {
+		case "/config.php", "/config.php.bak", "/index.html~":
+			fmt.Fprint(w, r.URL.Path)
+		case "/index.html":
+			fmt.Fprint(w, "<title>home</title>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Notes.StackHints = []string{"PHP 8"}
+	rz.HTTP = srv.Client()
+
+	out, err := rz.Enum(context.Background(), []string{"config", "index.html"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var got []string
+	for _, r := range out {
+		got = append(got, strings.TrimPrefix(r.Endpoint, srv.URL))
+	}
+	want := []string{"/config.php", "/config.php.bak", "/index.html", "/index.html~"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if _, ok := hits.Load("/config.php.bak.bak"); ok {
+		t.Error("backups should not be permuted again")
+	}
+	if _, ok := hits.Load("/index.php"); ok {
+		t.Error("only extension-less words get stack extensions")
+	}
+}
+
+func TestEnum_StackHintsMatchWords(t *testing.T) {
+	var hits sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Store(r.URL.Path, true)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Notes.StackHints = []string{"JavaScript frontend", "ASP.NET"}
+	rz.HTTP = srv.Client()
+
+	if _, err := rz.Enum(context.Background(), []string{"login"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if _, ok := hits.Load("/login.aspx"); !ok {
+		t.Error("ASP.NET should give .aspx")
+	}
+	for _, p := range []string{"/login.jsp", "/login.asp"} {
+		if _, ok := hits.Load(p); ok {
+			t.Errorf("%s tried, hints only match whole words", p)
+		}
+	}
+}
+
+func TestEnum_ExtPlaceholder(t *testing.T) {
+	var hits sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Store(r.URL.EscapedPath(), true)
+		http.NotFound(w, r)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.HTTP = srv.Client()
+
+	words := config.NormalizeWords([]string{"index.%EXT%"})
+	if _, err := rz.Enum(context.Background(), words); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	hits.Range(func(k, _ any) bool {
+		if strings.Contains(k.(string), "EXT") {
+			t.Errorf("placeholder sent as is: %s", k)
+		}
+		return true
+	})
+
+	rz.Notes.StackHints = []string{"PHP"}
+	if _, err := rz.Enum(context.Background(), words); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if _, ok := hits.Load("/index.php"); !ok {
+		t.Error("placeholder not filled in with the stack extensions")
+	}
+}
+
+func TestEnum_Redirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/new/", http.StatusMovedPermanently)
+		case "/new/":
+			http.Redirect(w, r, "/new/home", http.StatusFound)
+		case "/new/home":
+			fmt.Fprint(w, "home")
+		case "/account":
+			http.Redirect(w, r, "http://sso.example/login", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	testCases := []struct {
+		policy  string
+		old     []config.RedirectHop
+		account []config.RedirectHop
+	}{
+		{config.RedirectNone, nil, nil},
+		{
+			config.RedirectSameHost,
+			[]config.RedirectHop{{URL: srv.URL + "/new/", StatusCode: 302, Location: "/new/home"}, {URL: srv.URL + "/new/home", StatusCode: 200}},
+			[]config.RedirectHop{{URL: "http://sso.example/login", Skipped: "other host"}},
+		},
+		{
+			config.RedirectFollow,
+			[]config.RedirectHop{{URL: srv.URL + "/new/", StatusCode: 302, Location: "/new/home"}, {URL: srv.URL + "/new/home", StatusCode: 200}},
+			[]config.RedirectHop{{URL: "http://sso.example/login", Skipped: "out of scope"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		var rz config.Razor
+		rz.Scope.Targets = []string{srv.URL}
+		rz.Enumeration.Redirects = tc.policy
+		rz.HTTP = config.NewHTTPClient(config.Limits{})
+
+		out, err := rz.Enum(context.Background(), []string{"old", "account"})
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", tc.policy, err)
+		}
+		if len(out) != 2 || out[0].StatusCode != 302 || out[1].StatusCode != 301 {
+			t.Fatalf("%s: got %#v", tc.policy, out)
+		}
+		if !reflect.DeepEqual(out[0].Redirects, tc.account) || !reflect.DeepEqual(out[1].Redirects, tc.old) {
+			t.Errorf("%s: got %+v / %+v", tc.policy, out[0].Redirects, out[1].Redirects)
+		}
+	}
+}
+
+func TestEnum_DeadServiceDoesNotStopHost(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin" {
+			http.NotFound(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	dead := httptest.NewServer(http.NotFoundHandler())
+	deadURL := dead.URL
+	dead.Close() // same host, nothing listening anymore
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{deadURL, srv.URL}
+	rz.HTTP = srv.Client()
+
+	words := []string{"a", "b", "c", "d", "e", "f", "g", "%zz", "admin"}
+	out, err := rz.Enum(context.Background(), words)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	var hit, badWord bool
+	for _, r := range out {
+		if r.Endpoint == srv.URL+"/admin" && r.StatusCode == http.StatusOK {
+			hit = true
+		}
+		if strings.HasPrefix(r.Endpoint, srv.URL) && strings.Contains(r.Endpoint, "%zz") && r.Failed() {
+			badWord = true
+		}
+	}
+	if !hit || !badWord {
+		t.Fatalf("got %#v", out)
+	}
+}
@@ -0,0 +1,379 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+type DirEnumRes struct {
+	Endpoint      string            `json:"endpoint"`
+	StatusCode    int               `json:"status_code"`
+	ContentLength int64             `json:"content_length"`
+	ContentType   string            `json:"content_type,omitempty"`
+	Title         string            `json:"title,omitempty"`
+	BodyHash      string            `json:"body_hash,omitempty"` // sha256 of the (first 1MB of the) body
+	Location      string            `json:"location,omitempty"`  // where a 3xx points to
+	Redirects     []RedirectHop     `json:"redirects,omitempty"` // hops followed after Location, see Enumeration.Redirects
+	Headers       map[string]string `json:"headers,omitempty"`   // see interestingHeaders
+	ResponseTime  time.Duration     `json:"response_time"`
+	Error         string            `json:"error,omitempty"` // set when the request failed even after retries
+}
+
+// response headers worth keeping for triage
+var interestingHeaders = []string{
+	"Server",
+	"X-Powered-By",
+	"X-AspNet-Version",
+	"X-Generator",
+	"Via",
+}
+
+func (r DirEnumRes) Failed() bool { return r.Error != "" }
+
+// after this many failures in a row a host is considered dead for enum
+const maxHostFailures = 5
+
+// workers is how many requests we keep in flight, see Limits.Concurrency
+func (cfg *Razor) workers() int {
+	if cfg.Limits.Concurrency <= 0 {
+		return 1
+	}
+	return cfg.Limits.Concurrency
+}
+
+// just worse gobuster lol
+// runs on a pool of Limits.Concurrency workers, the HTTP stack keeps each
+// host at its own rate. results come back sorted by endpoint. failed
+// requests end up in the results too (see DirEnumRes.Error), only setup
+// problems or a cancelled ctx return an error.
+// words get the extensions of the stack each base runs (see StackHints,
+// dirs below a base inherit them) and every file found
+// gets its backup variants tried (defaults.BackupSuffixes). paths found by
+// Seed() and Fingerprint() are added to the first level of their base URL.
+func (cfg *Razor) Enum(ctx context.Context, wordlist []string) ([]DirEnumRes, error) {
+	guard, err := cfg.Guard()
+	if err != nil {
+		return nil, err
+	}
+	client, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+
+	budget, err := cfg.Budget()
+	if err != nil {
+		return nil, err
+	}
+
+	bases, err := cfg.WebTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &enumRun{
+		guard:     guard,
+		client:    client,
+		budget:    budget,
+		workers:   cfg.workers(),
+		maxDepth:  cfg.Enumeration.RecursionDepth,
+		redirects: cfg.Enumeration.Redirects,
+		stopped:   map[string]bool{},
+		failures:  map[string]int{},
+		seen:      map[string]bool{},
+	}
+
+	var level []enumDir
+	for _, base := range bases {
+		u, err := url.Parse(base)
+		if err != nil || !cfg.inFocus(u.Hostname()) {
+			continue
+		}
+		exts := stackExtensions(cfg.StackHints(base))
+		if n := placeholderWords(wordlist); n > 0 && len(exts) == 0 {
+			fmt.Printf("[~] %s: no stack extensions known, skipping %d %s words\n", base, n, extPlaceholder)
+		}
+		level = append(level, enumDir{
+			host:  u.Hostname(),
+			base:  base,
+			extra: cfg.seeds[base],
+			exts:  exts,
+		})
+		e.seen[base] = true
+	}
+
+	// breadth first: every level finishes before we dig into what it found
+	words := wordlist
+	for depth := 0; len(level) > 0 && err == nil; depth++ {
+		if depth == 1 && len(cfg.Enumeration.RecursionWordlist) > 0 {
+			words = cfg.Enumeration.RecursionWordlist
+		}
+		level, err = e.runLevel(ctx, level, words, depth)
+	}
+
+	sort.Slice(e.res, func(i, j int) bool { return e.res[i].Endpoint < e.res[j].Endpoint })
+	return e.res, err
+}
+
+// enumDir is one directory we throw the wordlist at
+type enumDir struct {
+	host  string
+	base  string // always ends with "/"
+	depth int
+	extra []string // words just for this dir (seeds)
+	exts  []string // stack extensions of the base this dir is under
+}
+
+// service is scheme://host:port of dir. a host can have a dead http and
+// a live https, failures are counted per service.
+func (d enumDir) service() string {
+	u, err := url.Parse(d.base)
+	if err != nil {
+		return d.base
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// runLevel enumerates dirs and returns the directories found in them
+// (nothing once maxDepth is reached)
+func (e *enumRun) runLevel(ctx context.Context, dirs []enumDir, words []string, depth int) ([]enumDir, error) {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(e.workers)
+
+	e.next = nil
+	for _, dir := range dirs {
+		dir.depth = depth
+
+		// learn what "not found" looks like here before trusting any 200
+		calib := calibrate(gctx, e.client, dir.base)
+
+		dirWords := withExtensions(words, dir.exts)
+		if len(dir.extra) > 0 {
+			// seeds are paths the target told us about, they don't get
+			// extensions, but a stray %EXT% among them still can't go out
+			dirWords = NormalizeWords(append(append([]string(nil), dirWords...), withExtensions(dir.extra, nil)...))
+		}
+		for _, word := range dirWords {
+			if gctx.Err() != nil {
+				break // cancelled, don't queue up the rest
+			}
+			g.Go(func() error {
+				return e.probe(gctx, dir, word, calib, false)
+			})
+		}
+	}
+
+	err := g.Wait()
+	if err == nil {
+		err = e.runBackups(ctx)
+	}
+	return e.next, err
+}
+
+// enumFile is a file found on the current level, its backups are next
+type enumFile struct {
+	dir   enumDir
+	word  string
+	calib calibration
+}
+
+// runBackups tries the backup variants of every file the level found, as
+// long as the host has the budget for all of them
+func (e *enumRun) runBackups(ctx context.Context) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(e.workers)
+
+	files := e.files
+	e.files = nil
+	skipped := map[string]bool{}
+	for _, f := range files {
+		names := backupNames(f.word)
+		if left := e.budget.Remaining(f.dir.host); left >= 0 && left < len(names) {
+			if !skipped[f.dir.host] {
+				fmt.Printf("[~] not enough budget left for backup permutations on %s\n", f.dir.host)
+				skipped[f.dir.host] = true
+			}
+			continue
+		}
+
+		for _, name := range names {
+			if gctx.Err() != nil {
+				break
+			}
+			g.Go(func() error {
+				return e.probe(gctx, f.dir, name, f.calib, true)
+			})
+		}
+	}
+	return g.Wait()
+}
+
+// enumRun is the state one Enum call shares between its workers
+type enumRun struct {
+	guard     *ScopeGuard
+	client    httpDoer
+	budget    *Budget
+	workers   int
+	maxDepth  int    // extra levels below the targets, 0 = no recursion
+	redirects string // Enumeration.Redirects, "" = none
+
+	mu       sync.Mutex
+	res      []DirEnumRes
+	stopped  map[string]bool // hosts out of budget or degraded, dead services
+	failures map[string]int  // failures in a row per service, see enumDir.service
+	seen     map[string]bool // dirs already enumerated
+	next     []enumDir       // dirs found on the current level
+	files    []enumFile      // files found on the current level
+}
+
+// probe requests dir+word and records what came back. backup = word is
+// already a permutation, don't permute or recurse any further.
+func (e *enumRun) probe(ctx context.Context, dir enumDir, word string, calib calibration, backup bool) error {
+	host, svc := dir.host, dir.service()
+
+	e.mu.Lock()
+	stop := e.stopped[host] || e.stopped[svc]
+	e.mu.Unlock()
+	if stop {
+		return nil
+	}
+
+	reqCtx, elapsed := withElapsed(ctx)
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, JoinURL(dir.base, word), nil)
+	if err != nil {
+		// a broken word, not a broken host: note it and move on
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.res = append(e.res, DirEnumRes{Endpoint: JoinURL(dir.base, word), Error: err.Error()})
+		return nil
+	}
+	if e.guard.PathExcluded(req.URL.Path) {
+		return nil
+	}
+
+	r, err := e.client.Do(req)
+	if errors.Is(err, ErrOutOfScope) {
+		return nil // already logged by the guard
+	}
+	if errors.Is(err, ErrOutsideWindow) {
+		return err // no window left for anyone, the whole run stops
+	}
+	if errors.Is(err, ErrBudgetExhausted) || errors.Is(err, ErrHostDegraded) {
+		e.mu.Lock()
+		if !e.stopped[host] {
+			fmt.Printf("[!] %v, stopping enum on %s\n", err, host)
+		}
+		e.stopped[host] = true
+		e.mu.Unlock()
+		return nil
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		e.res = append(e.res, DirEnumRes{Endpoint: req.URL.String(), Error: err.Error()})
+		e.failures[svc]++
+		if e.failures[svc] >= maxHostFailures && !e.stopped[svc] {
+			fmt.Printf("[!] %s failed %d times in a row, giving up on it\n", svc, e.failures[svc])
+			e.stopped[svc] = true
+		}
+		return nil
+	}
+	body := readBody(r)
+
+	e.mu.Lock()
+	e.failures[svc] = 0
+	e.mu.Unlock()
+	if !interesting(r.StatusCode) || calib.matches(r, body, req.URL.Path) {
+		return nil
+	}
+	res := newDirEnumRes(req, r, body, *elapsed)
+	if res.Location != "" && e.redirects != "" && e.redirects != RedirectNone {
+		res.Redirects = e.follow(ctx, req.URL, res.Location)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.res = append(e.res, res)
+
+	if backup {
+		return nil
+	}
+	isDir := looksLikeDir(req.URL, res)
+	if dir.depth < e.maxDepth && isDir {
+		sub := JoinURL(req.URL.String(), "/")
+		if !e.seen[sub] {
+			e.seen[sub] = true
+			e.next = append(e.next, enumDir{host: host, base: sub, exts: dir.exts})
+		}
+	}
+	if !isDir && strings.Contains(lastSegment(req.URL.Path), ".") {
+		e.files = append(e.files, enumFile{dir: dir, word: word, calib: calib})
+	}
+	return nil
+}
+
+// interesting: anything that exists, including what we're not allowed to see
+func interesting(status int) bool {
+	switch status {
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	}
+	return status >= 200 && status <= 400
+}
+
+// looksLikeDir: worth recursing into. trailing slash, a redirect to the
+// same path + "/", or an extension-less path that answered 200/401/403
+func looksLikeDir(u *url.URL, res DirEnumRes) bool {
+	if strings.HasSuffix(u.Path, "/") {
+		return true
+	}
+	if res.Location != "" {
+		loc, err := u.Parse(res.Location)
+		return err == nil && loc.Host == u.Host && loc.Path == u.Path+"/"
+	}
+	if strings.Contains(lastSegment(u.Path), ".") {
+		return false
+	}
+	switch res.StatusCode {
+	case http.StatusOK, http.StatusUnauthorized, http.StatusForbidden:
+		return true
+	}
+	return false
+}
+
+func newDirEnumRes(req *http.Request, r *http.Response, body []byte, elapsed time.Duration) DirEnumRes {
+	res := DirEnumRes{
+		Endpoint:      req.URL.String(),
+		StatusCode:    r.StatusCode,
+		ContentLength: r.ContentLength,
+		ContentType:   r.Header.Get("Content-Type"),
+		Title:         pageTitle(body),
+		BodyHash:      bodyHash(body),
+		Location:      r.Header.Get("Location"),
+		ResponseTime:  elapsed,
+	}
+	if res.ContentLength < 0 {
+		res.ContentLength = int64(len(body))
+	}
+	for _, h := range interestingHeaders {
+		if v := r.Header.Get(h); v != "" {
+			if res.Headers == nil {
+				res.Headers = map[string]string{}
+			}
+			res.Headers[h] = v
+		}
+	}
+	return res
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// kinds of things worth a line in the run log
+const (
+	EventBackoff  = "backoff"
+	EventRecover  = "recover"
+	EventIncident = "incident"
+)
+
+type Event struct {
+	Time   time.Time `json:"time"`
+	Host   string    `json:"host"`
+	Kind   string    `json:"kind"`
+	Detail string    `json:"detail"`
+}
+
+// EventLog is the run log shared by the HTTP stack and modules
+type EventLog struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+// Add records an event and echoes it right away
+func (l *EventLog) Add(host, kind, format string, args ...any) {
+	e := Event{Time: time.Now(), Host: host, Kind: kind, Detail: fmt.Sprintf(format, args...)}
+
+	l.mu.Lock()
+	l.events = append(l.events, e)
+	l.mu.Unlock()
+
+	fmt.Printf("[~] %s %s: %s\n", e.Kind, e.Host, e.Detail)
+}
+
+func (l *EventLog) Events() []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]Event(nil), l.events...)
+}
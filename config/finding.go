@@ -0,0 +1,35 @@
+package config
+
+type Severity string
+
+const (
+	SevInfo     Severity = "info"
+	SevLow      Severity = "low"
+	SevMedium   Severity = "medium"
+	SevHigh     Severity = "high"
+	SevCritical Severity = "critical"
+)
+
+// Weight is how much a finding of this severity bumps a host's risk score
+func (s Severity) Weight() int {
+	switch s {
+	case SevCritical:
+		return 10
+	case SevHigh:
+		return 7
+	case SevMedium:
+		return 4
+	case SevLow:
+		return 1
+	}
+	return 0
+}
+
+// Finding is anything worth a line in the report
+type Finding struct {
+	Host     string   `json:"host"`
+	Endpoint string   `json:"endpoint,omitempty"`
+	Title    string   `json:"title"`
+	Severity Severity `json:"severity"`
+	Detail   string   `json:"detail,omitempty"`
+}
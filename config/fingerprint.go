@@ -0,0 +1,370 @@
+package config
+
+import (
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// signatureDB is what Fingerprint knows about, see signatures.json
+//
+//go:embed signatures.json
+var signatureDB []byte
+
+// signature is one technology in signatures.json. patterns are regexes,
+// "" = being there is enough, the first capture group is the version.
+type signature struct {
+	Name    string            `json:"name"`
+	Hints   []string          `json:"hints"`   // extra Notes.StackHints, see defaults.StackExtensions
+	Headers map[string]string `json:"headers"` // header -> pattern
+	Cookies []string          `json:"cookies"` // cookie name prefixes
+	Meta    map[string]string `json:"meta"`    // <meta name=...> -> pattern on content
+	Body    []string          `json:"body"`    // patterns on the landing page
+	Favicon []int32           `json:"favicon"` // shodan style favicon hashes
+	Paths   []sigPath         `json:"paths"`   // well-known paths, cost a request each
+	Words   []string          `json:"words"`   // seeded into Enum where this runs
+}
+
+type sigPath struct {
+	Path   string `json:"path"`
+	Status int    `json:"status"` // 0 = any
+	Body   string `json:"body"`   // pattern, "" = any
+}
+
+type compiledSig struct {
+	signature
+	headers map[string]*regexp.Regexp
+	meta    map[string]*regexp.Regexp
+	body    []*regexp.Regexp
+	paths   []*regexp.Regexp // same order as Paths
+}
+
+var loadSignatures = sync.OnceValues(func() ([]compiledSig, error) {
+	var raw []signature
+	if err := json.Unmarshal(signatureDB, &raw); err != nil {
+		return nil, fmt.Errorf("signatures.json: %w", err)
+	}
+
+	sigs := make([]compiledSig, 0, len(raw))
+	for _, s := range raw {
+		c := compiledSig{signature: s, headers: map[string]*regexp.Regexp{}, meta: map[string]*regexp.Regexp{}}
+		var err error
+		compile := func(p string) *regexp.Regexp {
+			re, e := regexp.Compile(p)
+			if e != nil && err == nil {
+				err = fmt.Errorf("signatures.json: %s: %w", s.Name, e)
+			}
+			return re
+		}
+		for h, p := range s.Headers {
+			c.headers[h] = compile(p)
+		}
+		for m, p := range s.Meta {
+			c.meta[strings.ToLower(m)] = compile(p)
+		}
+		for _, p := range s.Body {
+			c.body = append(c.body, compile(p))
+		}
+		for _, p := range s.Paths {
+			c.paths = append(c.paths, compile(p.Body))
+		}
+		if err != nil {
+			return nil, err
+		}
+		sigs = append(sigs, c)
+	}
+	return sigs, nil
+})
+
+// Tech is one technology spotted on a web target
+type Tech struct {
+	URL      string   `json:"url"`
+	Name     string   `json:"name"`
+	Version  string   `json:"version,omitempty"`
+	Evidence []string `json:"evidence"`
+}
+
+var (
+	metaTagRe  = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttrRe = regexp.MustCompile(`(?is)\b(name|property|content)\s*=\s*["']([^"']*)["']`)
+)
+
+// Fingerprint works out what every web target runs from headers, cookies,
+// meta tags, the favicon and a few well-known paths. detections become
+// stack hints for that target only (so Enum picks the right extensions
+// there, see StackHints) and each one's words get seeded into Enum for it.
+func (cfg *Razor) Fingerprint(ctx context.Context) ([]Tech, error) {
+	sigs, err := loadSignatures()
+	if err != nil {
+		return nil, err
+	}
+	client, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+	bases, err := cfg.WebTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	var techs []Tech
+	for _, base := range bases {
+		if ctx.Err() != nil {
+			return techs, ctx.Err()
+		}
+		u, err := url.Parse(base)
+		if err != nil || !cfg.inFocus(u.Hostname()) {
+			continue
+		}
+
+		found := fingerprintBase(ctx, client, base, sigs)
+		for _, f := range found {
+			techs = append(techs, f.tech)
+			cfg.addStackHints(base, append([]string{f.sig.Name}, f.sig.Hints...)...)
+			cfg.addSeeds(base, f.sig.Words)
+		}
+	}
+	return techs, nil
+}
+
+type detection struct {
+	sig  compiledSig
+	tech Tech
+}
+
+func fingerprintBase(ctx context.Context, client httpDoer, base string, sigs []compiledSig) []detection {
+	get := func(p string) (*http.Response, []byte) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, JoinURL(base, p), nil)
+		if err != nil {
+			return nil, nil
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, nil
+		}
+		return resp, readBody(resp)
+	}
+
+	page, body := get("/")
+	if page == nil {
+		return nil
+	}
+	meta := metaTags(body)
+	cookies := map[string]bool{}
+	for _, c := range page.Cookies() {
+		cookies[c.Name] = true
+	}
+
+	var favicon int32
+	var haveFavicon bool
+	if resp, ico := get("favicon.ico"); resp != nil && resp.StatusCode == http.StatusOK && len(ico) > 0 &&
+		!strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		favicon, haveFavicon = faviconHash(ico), true
+	}
+
+	// well-known paths, each asked once no matter how many signatures use it
+	var calib calibration
+	paths := map[string]*http.Response{}
+	pathBodies := map[string][]byte{}
+	for _, s := range sigs {
+		for _, p := range s.Paths {
+			if _, done := paths[p.Path]; done || ctx.Err() != nil {
+				continue
+			}
+			if calib == nil {
+				calib = calibrate(ctx, client, base)
+			}
+			resp, b := get(p.Path)
+			if resp != nil && calib.matches(resp, b, p.Path) {
+				resp = nil // soft-404
+			}
+			paths[p.Path], pathBodies[p.Path] = resp, b
+		}
+	}
+
+	var out []detection
+	for _, s := range sigs {
+		var evidence []string
+		version := ""
+		hit := func(ev string, m []string) {
+			evidence = append(evidence, ev)
+			if version == "" && len(m) > 1 {
+				version = m[1]
+			}
+		}
+
+		for _, h := range sortedKeys(s.headers) {
+			if v := page.Header.Get(h); v != "" {
+				if m := s.headers[h].FindStringSubmatch(v); m != nil {
+					hit(fmt.Sprintf("header %s: %s", h, v), m)
+				}
+			}
+		}
+		for _, prefix := range s.Cookies {
+			for name := range cookies {
+				if strings.HasPrefix(name, prefix) {
+					hit("cookie "+name, nil)
+					break
+				}
+			}
+		}
+		for _, name := range sortedKeys(s.meta) {
+			if content, ok := meta[name]; ok {
+				if m := s.meta[name].FindStringSubmatch(content); m != nil {
+					hit(fmt.Sprintf("meta %s: %s", name, content), m)
+				}
+			}
+		}
+		for _, re := range s.body {
+			if re.Match(body) {
+				hit("body "+re.String(), nil)
+			}
+		}
+		for _, h := range s.Favicon {
+			if haveFavicon && h == favicon {
+				hit("favicon "+strconv.Itoa(int(h)), nil)
+			}
+		}
+		for i, p := range s.Paths {
+			resp := paths[p.Path]
+			if resp == nil || (p.Status != 0 && resp.StatusCode != p.Status) || !s.paths[i].Match(pathBodies[p.Path]) {
+				continue
+			}
+			hit(fmt.Sprintf("path %s (%d)", p.Path, resp.StatusCode), nil)
+		}
+
+		if len(evidence) == 0 {
+			continue
+		}
+		sort.Strings(evidence)
+		out = append(out, detection{sig: s, tech: Tech{URL: base, Name: s.Name, Version: version, Evidence: evidence}})
+	}
+	return out
+}
+
+func sortedKeys(m map[string]*regexp.Regexp) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// metaTags maps <meta name=... content=...> (lowercase names)
+func metaTags(body []byte) map[string]string {
+	tags := map[string]string{}
+	for _, tag := range metaTagRe.FindAll(body, -1) {
+		var name, content string
+		for _, a := range metaAttrRe.FindAllSubmatch(tag, -1) {
+			switch strings.ToLower(string(a[1])) {
+			case "name", "property":
+				name = strings.ToLower(string(a[2]))
+			case "content":
+				content = string(a[2])
+			}
+		}
+		if name != "" {
+			tags[name] = content
+		}
+	}
+	return tags
+}
+
+// faviconHash is the shodan http.favicon.hash: murmur3 over the base64 of
+// the icon, wrapped at 76 chars like python's base64.encodebytes
+func faviconHash(ico []byte) int32 {
+	enc := base64.StdEncoding.EncodeToString(ico)
+	var b strings.Builder
+	for len(enc) > 76 {
+		b.WriteString(enc[:76])
+		b.WriteByte('\n')
+		enc = enc[76:]
+	}
+	b.WriteString(enc)
+	b.WriteByte('\n')
+	return int32(murmur3([]byte(b.String())))
+}
+
+// murmur3 is MurmurHash3 x86 32bit, seed 0
+func murmur3(data []byte) uint32 {
+	const c1, c2 = 0xcc9e2d51, 0x1b873593
+	var h uint32
+
+	n := len(data) / 4
+	for i := 0; i < n; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	tail := data[n*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
+
+// addStackHints adds hints detected on base, case-insensitively
+func (cfg *Razor) addStackHints(base string, hints ...string) {
+	if cfg.hints == nil {
+		cfg.hints = map[string][]string{}
+	}
+	cfg.hints[base] = mergeHints(cfg.hints[base], hints)
+}
+
+// StackHints is what base runs: the user's Notes.StackHints (those go for
+// every target) plus whatever Fingerprint detected on base
+func (cfg *Razor) StackHints(base string) []string {
+	return mergeHints(append([]string(nil), cfg.Notes.StackHints...), cfg.hints[base])
+}
+
+func mergeHints(have, hints []string) []string {
+	for _, h := range hints {
+		known := false
+		for _, k := range have {
+			if strings.EqualFold(k, h) {
+				known = true
+				break
+			}
+		}
+		if !known {
+			have = append(have, h)
+		}
+	}
+	return have
+}
@@ -0,0 +1,104 @@
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestFingerprint(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx/1.24.0")
+		switch r.URL.Path {
+		case "/":
+			http.SetCookie(w, &http.Cookie{Name: "PHPSESSID", Value: "x"})
+			fmt.Fprint(w, `<html><head><meta content="WordPress 6.4.2" name="generator"></head></html>`)
+		case "/wp-login.php":
+			fmt.Fprint(w, `<input name="log" id="user_login">`)
+		case "/wp-admin/", "/config.php":
+			fmt.Fprint(w, r.URL.Path)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Notes.StackHints = []string{"wordpress"}
+	rz.HTTP = srv.Client()
+
+	techs, err := rz.Fingerprint(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	got := map[string]config.Tech{}
+	for _, tech := range techs {
+		got[tech.Name] = tech
+	}
+	if len(got) != 3 || got["Nginx"].Version != "1.24.0" || got["WordPress"].Version != "6.4.2" || got["PHP"].Name == "" {
+		t.Fatalf("got %#v", techs)
+	}
+	if ev := strings.Join(got["WordPress"].Evidence, "|"); !strings.Contains(ev, "path /wp-login.php (200)") {
+		t.Errorf("wordpress evidence: %v", ev)
+	}
+
+	// user hints stay global, detections get merged in for this target only
+	if hints := strings.Join(rz.StackHints(srv.URL+"/"), ","); hints != "wordpress,Nginx,PHP" {
+		t.Errorf("got hints %s", hints)
+	}
+	if hints := strings.Join(rz.Notes.StackHints, ","); hints != "wordpress" {
+		t.Errorf("detections leaked into Notes.StackHints: %s", hints)
+	}
+
+	// .php from the hints, /wp-admin/ from the signature's words
+	out, err := rz.Enum(context.Background(), []string{"config"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(out) != 2 || out[0].Endpoint != srv.URL+"/config.php" || out[1].Endpoint != srv.URL+"/wp-admin/" {
+		t.Fatalf("got %#v", out)
+	}
+}
+
+func TestFingerprint_HintsStayOnTheirHost(t *testing.T) {
+	express := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Powered-By", "Express")
+		http.NotFound(w, r)
+	}))
+	defer express.Close()
+
+	var plainPaths sync.Map
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		plainPaths.Store(r.URL.Path, true)
+		http.NotFound(w, r)
+	}))
+	defer plain.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{express.URL, plain.URL}
+	rz.HTTP = express.Client()
+
+	if _, err := rz.Fingerprint(context.Background()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if _, err := rz.Enum(context.Background(), []string{"config"}); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	if hints := rz.StackHints(plain.URL + "/"); len(hints) != 0 {
+		t.Errorf("plain host got hints %v", hints)
+	}
+	if _, ok := plainPaths.Load("/config.js"); ok {
+		t.Errorf("express extensions used on the plain host")
+	}
+	if _, ok := plainPaths.Load("/config"); !ok {
+		t.Errorf("plain host not enumerated")
+	}
+}
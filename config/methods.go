@@ -0,0 +1,294 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// verbs we flag when a server admits to them, and how bad that is
+var dangerousMethods = map[string]Severity{
+	"PUT":       SevMedium,
+	"DELETE":    SevMedium,
+	"TRACE":     SevLow,
+	"CONNECT":   SevLow,
+	"PROPFIND":  SevLow, // WebDAV from here on
+	"PROPPATCH": SevLow,
+	"MKCOL":     SevLow,
+	"COPY":      SevLow,
+	"MOVE":      SevLow,
+}
+
+// headers frameworks use to tunnel a verb through POST
+var overrideHeaders = []string{"X-HTTP-Method-Override", "X-HTTP-Method", "X-Method-Override"}
+
+// verbTry is one way of getting a verb to the app
+type verbTry struct {
+	method string
+	hdr    http.Header
+	label  string
+}
+
+// overrides tunnels verb through POST with every override header
+func overrides(verb string) []verbTry {
+	tries := make([]verbTry, 0, len(overrideHeaders))
+	for _, h := range overrideHeaders {
+		tries = append(tries, verbTry{http.MethodPost, http.Header{h: {verb}}, "POST + " + h + ": " + verb})
+	}
+	return tries
+}
+
+// Methods looks past GET on every endpoint Enum found: OPTIONS/Allow,
+// TRACE echo, verb tampering on 401/403s and, with Scope.AllowIntrusive,
+// PUT/DELETE on a throwaway file of our own. never touches existing content.
+func (cfg *Razor) Methods(ctx context.Context, endpoints []DirEnumRes) ([]Finding, error) {
+	client, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &methodRun{
+		client:    client,
+		intrusive: cfg.Scope.AllowIntrusive,
+		allows:    map[string]bool{},
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(cfg.workers())
+	for _, e := range endpoints {
+		if e.Failed() {
+			continue
+		}
+		u, err := url.Parse(e.Endpoint)
+		if err != nil || !cfg.inFocus(u.Hostname()) {
+			continue
+		}
+		if gctx.Err() != nil {
+			break
+		}
+		g.Go(func() error {
+			m.endpoint(gctx, u, e.StatusCode)
+			if m.closed.Load() {
+				return ErrOutsideWindow
+			}
+			return gctx.Err()
+		})
+	}
+	err = g.Wait()
+
+	sort.SliceStable(m.findings, func(i, j int) bool {
+		if m.findings[i].Endpoint != m.findings[j].Endpoint {
+			return m.findings[i].Endpoint < m.findings[j].Endpoint
+		}
+		return m.findings[i].Title < m.findings[j].Title
+	})
+	return m.findings, err
+}
+
+type methodRun struct {
+	client    httpDoer
+	intrusive bool
+	closed    atomic.Bool // time window is over, stop
+
+	mu       sync.Mutex
+	findings []Finding
+	allows   map[string]bool // host + Allow value already reported
+}
+
+func (m *methodRun) add(f Finding) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.findings = append(m.findings, f)
+}
+
+// send fires one request, the body comes back already read
+func (m *methodRun) send(ctx context.Context, method, target string, hdr http.Header, body []byte) (*http.Response, []byte, error) {
+	var rd io.Reader
+	if body != nil {
+		rd = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, rd)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range hdr {
+		req.Header[k] = v
+	}
+	resp, err := m.client.Do(req)
+	if errors.Is(err, ErrOutsideWindow) {
+		m.closed.Store(true)
+		return nil, nil, err
+	}
+	if err != nil {
+		if !errors.Is(err, ErrOutOfScope) && ctx.Err() == nil {
+			fmt.Printf("[!] %s %s: %v\n", method, target, err)
+		}
+		return nil, nil, err
+	}
+	return resp, readBody(resp), nil
+}
+
+func (m *methodRun) endpoint(ctx context.Context, u *url.URL, status int) {
+	m.options(ctx, u)
+	m.trace(ctx, u)
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		m.bypass(ctx, u, status)
+	}
+	if m.intrusive && strings.HasSuffix(u.Path, "/") {
+		m.put(ctx, u)
+	}
+}
+
+// options reports dangerous verbs in Allow, once per host and Allow value
+func (m *methodRun) options(ctx context.Context, u *url.URL) {
+	resp, _, err := m.send(ctx, http.MethodOptions, u.String(), nil, nil)
+	if err != nil {
+		return
+	}
+	allow := resp.Header.Get("Allow")
+	if allow == "" {
+		allow = resp.Header.Get("Public") // IIS
+	}
+
+	var bad []string
+	sev := SevInfo
+	for _, v := range strings.Split(allow, ",") {
+		v = strings.ToUpper(strings.TrimSpace(v))
+		if s, ok := dangerousMethods[v]; ok {
+			bad = append(bad, v)
+			if s.Weight() > sev.Weight() {
+				sev = s
+			}
+		}
+	}
+	if len(bad) == 0 {
+		return
+	}
+
+	key := u.Host + "|" + allow
+	m.mu.Lock()
+	seen := m.allows[key]
+	m.allows[key] = true
+	m.mu.Unlock()
+	if seen {
+		return
+	}
+
+	m.add(Finding{
+		Host:     u.Hostname(),
+		Endpoint: u.String(),
+		Title:    "Dangerous HTTP methods advertised",
+		Severity: sev,
+		Detail:   fmt.Sprintf("OPTIONS says Allow: %s (%s)", allow, strings.Join(bad, ", ")),
+	})
+}
+
+// trace checks whether TRACE echoes our request back (XST)
+func (m *methodRun) trace(ctx context.Context, u *url.URL) {
+	token := randomToken()
+	hdr := http.Header{"X-Razor-Trace": {token}}
+	resp, body, err := m.send(ctx, http.MethodTrace, u.String(), hdr, nil)
+	if err != nil || resp.StatusCode != http.StatusOK || !bytes.Contains(body, []byte(token)) {
+		return
+	}
+	m.add(Finding{
+		Host:     u.Hostname(),
+		Endpoint: u.String(),
+		Title:    "TRACE enabled",
+		Severity: SevLow,
+		Detail:   "TRACE echoes request headers back (cross-site tracing)",
+	})
+}
+
+// bypass retries a 401/403 with other verbs. HEAD and a made-up verb are
+// harmless, POST and override headers only go out when intrusive.
+func (m *methodRun) bypass(ctx context.Context, u *url.URL, status int) {
+	tries := []verbTry{
+		{method: http.MethodHead, label: "HEAD"},
+		{method: "RAZOR", label: "made-up verb RAZOR"},
+	}
+	if m.intrusive {
+		tries = append(tries, verbTry{method: http.MethodPost, label: "POST"})
+		tries = append(tries, overrides(http.MethodGet)...)
+	}
+
+	for _, a := range tries {
+		if ctx.Err() != nil {
+			return
+		}
+		resp, _, err := m.send(ctx, a.method, u.String(), a.hdr, nil)
+		if err != nil || resp.StatusCode < 200 || resp.StatusCode > 299 {
+			continue
+		}
+		m.add(Finding{
+			Host:     u.Hostname(),
+			Endpoint: u.String(),
+			Title:    fmt.Sprintf("%d bypass via HTTP verb", status),
+			Severity: SevHigh,
+			Detail:   fmt.Sprintf("GET got %d, %s got %s", status, a.label, resp.Status),
+		})
+		return // one is enough
+	}
+}
+
+// put uploads a throwaway file into dir (directly or through an override
+// header), checks it's really there and deletes it again
+func (m *methodRun) put(ctx context.Context, dir *url.URL) {
+	token := randomToken()
+	file := JoinURL(dir.String(), token+".txt")
+
+	var how string
+	tries := append([]verbTry{{method: http.MethodPut, label: "PUT"}}, overrides(http.MethodPut)...)
+	for _, t := range tries {
+		resp, _, err := m.send(ctx, t.method, file, t.hdr, []byte(token))
+		if err != nil {
+			return
+		}
+		if resp.StatusCode >= 200 && resp.StatusCode <= 299 && m.fetches(ctx, file, token) {
+			how = t.label
+			break
+		}
+	}
+	if how == "" {
+		return
+	}
+
+	host := dir.Hostname()
+	m.add(Finding{
+		Host:     host,
+		Endpoint: file,
+		Title:    "Arbitrary file upload via HTTP PUT",
+		Severity: SevHigh,
+		Detail:   fmt.Sprintf("%s created %s and GET served it back", how, file),
+	})
+
+	// clean up after ourselves, and that tells us about DELETE too
+	resp, _, err := m.send(ctx, http.MethodDelete, file, nil, nil)
+	if err == nil && resp.StatusCode >= 200 && resp.StatusCode <= 299 && !m.fetches(ctx, file, token) {
+		m.add(Finding{
+			Host:     host,
+			Endpoint: file,
+			Title:    "Arbitrary file deletion via HTTP DELETE",
+			Severity: SevHigh,
+			Detail:   fmt.Sprintf("DELETE removed %s (our own upload)", file),
+		})
+		return
+	}
+	fmt.Printf("[!] could not delete %s, clean it up by hand\n", file)
+}
+
+// fetches reports whether GET target serves token
+func (m *methodRun) fetches(ctx context.Context, target, token string) bool {
+	resp, body, err := m.send(ctx, http.MethodGet, target, nil, nil)
+	return err == nil && resp.StatusCode == http.StatusOK && bytes.Contains(body, []byte(token))
+}
@@ -0,0 +1,115 @@
+package config_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+// davServer: /admin is 403 unless you ask with HEAD, /uploads/ takes PUTs
+func davServer(t *testing.T) (*httptest.Server, *sync.Map, *sync.Map) {
+	var files, verbs sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verbs.Store(r.Method, true)
+		if r.Method == http.MethodTrace {
+			r.Header.Write(w)
+			return
+		}
+
+		switch {
+		case r.URL.Path == "/admin":
+			if r.Method != http.MethodHead {
+				w.WriteHeader(http.StatusForbidden)
+			}
+		case r.URL.Path == "/uploads/" && r.Method == http.MethodOptions:
+			w.Header().Set("Allow", "GET, HEAD, PUT, DELETE")
+		case strings.HasPrefix(r.URL.Path, "/uploads/") && r.URL.Path != "/uploads/":
+			switch r.Method {
+			case http.MethodPut:
+				b, _ := io.ReadAll(r.Body)
+				files.Store(r.URL.Path, b)
+				w.WriteHeader(http.StatusCreated)
+			case http.MethodDelete:
+				files.Delete(r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+			default:
+				b, ok := files.Load(r.URL.Path)
+				if !ok {
+					http.NotFound(w, r)
+					return
+				}
+				w.Write(b.([]byte))
+			}
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &files, &verbs
+}
+
+func titles(fs []config.Finding) map[string]config.Severity {
+	out := map[string]config.Severity{}
+	for _, f := range fs {
+		out[f.Title] = f.Severity
+	}
+	return out
+}
+
+func TestMethods_Safe(t *testing.T) {
+	srv, _, verbs := davServer(t)
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.HTTP = srv.Client()
+
+	out, err := rz.Methods(context.Background(), []config.DirEnumRes{
+		{Endpoint: srv.URL + "/admin", StatusCode: 403},
+		{Endpoint: srv.URL + "/uploads/", StatusCode: 200},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	got := titles(out)
+	if got["403 bypass via HTTP verb"] != config.SevHigh ||
+		got["Dangerous HTTP methods advertised"] != config.SevMedium ||
+		got["TRACE enabled"] != config.SevLow || len(got) != 3 {
+		t.Fatalf("got %#v", out)
+	}
+	for _, v := range []string{http.MethodPut, http.MethodDelete, http.MethodPost} {
+		if _, ok := verbs.Load(v); ok {
+			t.Errorf("%s sent without allow_intrusive", v)
+		}
+	}
+}
+
+func TestMethods_Intrusive(t *testing.T) {
+	srv, files, _ := davServer(t)
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Scope.AllowIntrusive = true
+	rz.HTTP = srv.Client()
+
+	out, err := rz.Methods(context.Background(), []config.DirEnumRes{
+		{Endpoint: srv.URL + "/uploads/", StatusCode: 200},
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	got := titles(out)
+	if got["Arbitrary file upload via HTTP PUT"] != config.SevHigh ||
+		got["Arbitrary file deletion via HTTP DELETE"] != config.SevHigh {
+		t.Fatalf("got %#v", out)
+	}
+	files.Range(func(k, _ any) bool {
+		t.Errorf("%v left behind", k)
+		return true
+	})
+}
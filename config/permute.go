@@ -0,0 +1,98 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/ZeroPvlse/razor/defaults"
+)
+
+// stackExtensions picks extensions from defaults.StackExtensions for the
+// given hints ("WordPress on Nginx" -> .php). stacks match whole tokens
+// only, so "JavaScript" isn't java and "ASP.NET" is .net, not classic asp.
+// sorted, no dupes.
+func stackExtensions(hints []string) []string {
+	seen := map[string]bool{}
+	var exts []string
+	for _, hint := range hints {
+		for _, tok := range hintTokens(hint) {
+			for _, ext := range defaults.StackExtensions[tok] {
+				if !seen[ext] {
+					seen[ext] = true
+					exts = append(exts, ext)
+				}
+			}
+		}
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// hintTokens splits a hint into lowercase words plus the dotted tail of
+// each word: "Microsoft-IIS/10.0, ASP.NET" -> microsoft iis 10.0 .0 asp.net .net
+func hintTokens(hint string) []string {
+	var toks []string
+	words := strings.FieldsFunc(strings.ToLower(hint), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.'
+	})
+	for _, w := range words {
+		toks = append(toks, w)
+		for i := 1; i < len(w); i++ {
+			if w[i] == '.' {
+				toks = append(toks, w[i:])
+			}
+		}
+	}
+	return toks
+}
+
+// withExtensions adds word+ext for every extension-less word that isn't
+// clearly a directory and fills in %EXT% words with every ext. %EXT% words
+// are dropped when there are no exts, they'd only ever 404.
+func withExtensions(words, exts []string) []string {
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		if !strings.Contains(w, extPlaceholder) {
+			out = append(out, w)
+		}
+	}
+	if len(exts) == 0 {
+		return out
+	}
+	for _, w := range words {
+		if strings.Contains(w, extPlaceholder) {
+			for _, ext := range exts {
+				out = append(out, strings.ReplaceAll(w, extPlaceholder, strings.TrimPrefix(ext, ".")))
+			}
+			continue
+		}
+		if strings.HasSuffix(w, "/") || strings.Contains(lastSegment(w), ".") {
+			continue
+		}
+		for _, ext := range exts {
+			out = append(out, w+ext)
+		}
+	}
+	return NormalizeWords(out)
+}
+
+// backupNames is what an editor or admin might have left next to word
+func backupNames(word string) []string {
+	names := make([]string, 0, len(defaults.BackupSuffixes))
+	for _, s := range defaults.BackupSuffixes {
+		names = append(names, word+s)
+	}
+	return names
+}
+
+// placeholderWords counts the %EXT% words in words
+func placeholderWords(words []string) int {
+	n := 0
+	for _, w := range words {
+		if strings.Contains(w, extPlaceholder) {
+			n++
+		}
+	}
+	return n
+}
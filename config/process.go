@@ -0,0 +1,61 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+// pauser pauses an external tool for one or more reasons (time window,
+// degraded host, ...) and only lets it go once every reason is gone
+type pauser struct {
+	p    *os.Process
+	name string
+
+	mu      sync.Mutex
+	reasons map[string]struct{}
+}
+
+func newPauser(p *os.Process, name string) *pauser {
+	return &pauser{p: p, name: name, reasons: map[string]struct{}{}}
+}
+
+func (ps *pauser) pause(reason string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if _, ok := ps.reasons[reason]; ok {
+		return
+	}
+	ps.reasons[reason] = struct{}{}
+	if len(ps.reasons) > 1 {
+		return // already stopped
+	}
+
+	fmt.Printf("[~] %s, pausing %s\n", reason, ps.name)
+	if err := pauseProcess(ps.p); err != nil {
+		fmt.Printf("[!] can't pause %s (%v), killing it\n", ps.name, err)
+		_ = ps.p.Kill()
+	}
+}
+
+func (ps *pauser) resume(reason string) {
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if _, ok := ps.reasons[reason]; !ok {
+		return
+	}
+	delete(ps.reasons, reason)
+	if len(ps.reasons) > 0 {
+		return
+	}
+
+	fmt.Printf("[~] resuming %s\n", ps.name)
+	_ = resumeProcess(ps.p)
+}
+
+func (ps *pauser) kill(reason string) {
+	fmt.Printf("[!] %s, killing %s\n", reason, ps.name)
+	_ = ps.p.Kill()
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package config
+
+import (
+	"errors"
+	"os"
+)
+
+var errNoPause = errors.New("pausing processes isn't supported on this OS")
+
+func pauseProcess(p *os.Process) error {
+	return errNoPause
+}
+
+func resumeProcess(p *os.Process) error {
+	return errNoPause
+}
@@ -0,0 +1,153 @@
+package config
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Ullaakut/nmap/v3"
+)
+
+// serviceRisk is how juicy an exposed service is (by nmap service name).
+// anything not listed counts as 1.
+var serviceRisk = map[string]int{
+	"telnet":        5,
+	"ftp":           3,
+	"tftp":          3,
+	"microsoft-ds":  4,
+	"netbios-ssn":   3,
+	"ms-wbt-server": 4, // RDP
+	"vnc":           4,
+	"mysql":         4,
+	"ms-sql-s":      4,
+	"oracle-tns":    4,
+	"postgresql":    4,
+	"redis":         4,
+	"mongodb":       4,
+	"ldap":          3,
+	"snmp":          3,
+	"nfs":           3,
+	"rpcbind":       2,
+	"http":          2,
+	"https":         2,
+	"http-proxy":    2,
+	"ssl/http":      2,
+}
+
+// HostScore is one host with everything that made it (un)interesting
+type HostScore struct {
+	Host      string   `json:"host"`
+	Names     []string `json:"names,omitempty"`
+	Score     int      `json:"score"`
+	OpenPorts []int    `json:"open_ports"`
+	Services  []string `json:"services"`
+	Endpoints int      `json:"endpoints"`
+	Findings  int      `json:"findings"`
+}
+
+// matches reports whether h is the host behind name (IP or hostname)
+func (h HostScore) matches(name string) bool {
+	name = strings.ToLower(name)
+	if name == h.Host {
+		return true
+	}
+	for _, n := range h.Names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RankHosts scores every host by open ports, exposed services, discovered
+// endpoints and findings. highest risk first, ties broken by address.
+func RankHosts(run *nmap.Run, endpoints []DirEnumRes, findings []Finding) []HostScore {
+	if run == nil {
+		return nil
+	}
+
+	var ranked []HostScore
+	for _, host := range run.Hosts {
+		if len(host.Addresses) == 0 {
+			continue
+		}
+
+		hs := HostScore{Host: strings.ToLower(host.Addresses[0].Addr)}
+		for _, hn := range host.Hostnames {
+			hs.Names = append(hs.Names, strings.ToLower(hn.Name))
+		}
+
+		for _, port := range host.Ports {
+			if port.Status() != nmap.Open {
+				continue
+			}
+			hs.OpenPorts = append(hs.OpenPorts, int(port.ID))
+			hs.Score++
+
+			if name := port.Service.Name; name != "" {
+				hs.Services = append(hs.Services, name)
+				if w, ok := serviceRisk[name]; ok {
+					hs.Score += w
+				} else {
+					hs.Score++
+				}
+			}
+		}
+
+		for _, e := range endpoints {
+			if e.Failed() {
+				continue
+			}
+			if u, err := url.Parse(e.Endpoint); err == nil && hs.matches(u.Hostname()) {
+				hs.Endpoints++
+				hs.Score++
+			}
+		}
+		for _, f := range findings {
+			if hs.matches(f.Host) {
+				hs.Findings++
+				hs.Score += f.Severity.Weight()
+			}
+		}
+
+		ranked = append(ranked, hs)
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Host < ranked[j].Host
+	})
+	return ranked
+}
+
+// KeyHosts cuts ranked hosts down to Scope.MaxHosts (0 = all of them)
+func (cfg *Razor) KeyHosts(ranked []HostScore) []HostScore {
+	if cfg.Scope.MaxHosts <= 0 || len(ranked) <= cfg.Scope.MaxHosts {
+		return ranked
+	}
+	return ranked[:cfg.Scope.MaxHosts]
+}
+
+// Focus restricts the web and intrusive phases to the given hosts. an
+// empty list means nothing made the cut and those phases touch nothing,
+// call it only when Scope.MaxHosts asks for a cut.
+func (cfg *Razor) Focus(hosts []HostScore) {
+	cfg.focus = map[string]struct{}{}
+	for _, h := range hosts {
+		cfg.focus[h.Host] = struct{}{}
+		for _, n := range h.Names {
+			cfg.focus[n] = struct{}{}
+		}
+	}
+}
+
+// inFocus reports whether the phases should touch host
+func (cfg *Razor) inFocus(host string) bool {
+	if cfg.focus == nil {
+		return true
+	}
+	_, ok := cfg.focus[strings.ToLower(host)]
+	return ok
+}
@@ -0,0 +1,64 @@
+package config_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/Ullaakut/nmap/v3"
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func openPort(id uint16, service string) nmap.Port {
+	return nmap.Port{
+		ID:      id,
+		State:   nmap.State{State: "open"},
+		Service: nmap.Service{Name: service},
+	}
+}
+
+func TestRankHosts(t *testing.T) {
+	run := &nmap.Run{Hosts: []nmap.Host{
+		{Addresses: []nmap.Address{{Addr: "10.0.0.1"}}, Ports: []nmap.Port{openPort(22, "ssh")}},
+		{Addresses: []nmap.Address{{Addr: "10.0.0.2"}}, Ports: []nmap.Port{openPort(23, "telnet"), openPort(445, "microsoft-ds")}},
+		{Addresses: []nmap.Address{{Addr: "10.0.0.3"}}, Ports: []nmap.Port{openPort(80, "http")}},
+	}}
+	endpoints := []config.DirEnumRes{{Endpoint: "http://10.0.0.3/admin", StatusCode: 200}}
+	findings := []config.Finding{{Host: "10.0.0.3", Title: "exposed .git", Severity: config.SevCritical}}
+
+	ranked := config.RankHosts(run, endpoints, findings)
+	if len(ranked) != 3 || ranked[0].Host != "10.0.0.3" || ranked[1].Host != "10.0.0.2" {
+		t.Fatalf("got %+v", ranked)
+	}
+
+	var rz config.Razor
+	rz.Scope.MaxHosts = 2
+	if key := rz.KeyHosts(ranked); len(key) != 2 || key[1].Host != "10.0.0.2" {
+		t.Fatalf("got key hosts %+v", key)
+	}
+}
+
+func TestFocus_EmptyRanking(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Scope.MaxHosts = 1
+	rz.HTTP = srv.Client()
+
+	// the scan came back empty, nothing is a key host
+	rz.Focus(rz.KeyHosts(config.RankHosts(nil, nil, nil)))
+	out, err := rz.Enum(context.Background(), []string{"admin"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(out) != 0 || hits.Load() != 0 {
+		t.Fatalf("empty focus still enumerated: %#v (%d requests)", out, hits.Load())
+	}
+}
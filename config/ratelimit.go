@@ -0,0 +1,138 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	maxInterval  = 30 * time.Second // slowest we'll ever go after backing off
+	easeAfterOKs = 20               // clean responses in a row before speeding back up
+)
+
+// RateLimiter is a per-host token bucket with room for a single token, so
+// requests to one host are spaced evenly at Limits.RPSPerHost no matter how
+// many goroutines are hammering it. hosts don't slow each other down.
+// hosts that push back get slowed down on their own (see Backoff).
+type RateLimiter struct {
+	interval time.Duration // 0 = unlimited
+
+	mu    sync.Mutex
+	hosts map[string]*hostRate
+}
+
+type hostRate struct {
+	next     time.Time     // when host gets its next token
+	interval time.Duration // current spacing, >= the base one
+	oks      int           // clean responses since the last backoff
+}
+
+func NewRateLimiter(rps int) *RateLimiter {
+	l := &RateLimiter{hosts: map[string]*hostRate{}}
+	if rps > 0 {
+		l.interval = time.Second / time.Duration(rps)
+	}
+	return l
+}
+
+func (l *RateLimiter) host(host string) *hostRate {
+	host = strings.ToLower(host)
+	h, ok := l.hosts[host]
+	if !ok {
+		h = &hostRate{interval: l.interval}
+		l.hosts[host] = h
+	}
+	return h
+}
+
+// Wait blocks until host may send another request
+func (l *RateLimiter) Wait(ctx context.Context, host string) error {
+	// reserve a slot first, then sleep outside the lock
+	l.mu.Lock()
+	h := l.host(host)
+	now := time.Now()
+	at := h.next
+	if at.Before(now) {
+		at = now
+	}
+	h.next = at.Add(h.interval)
+	l.mu.Unlock()
+
+	if d := time.Until(at); d > 0 {
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return nil
+}
+
+// Backoff doubles host's spacing (capped at maxInterval) and holds it until
+// at least `until`. returns the new spacing.
+func (l *RateLimiter) Backoff(host string, until time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h := l.host(host)
+	h.oks = 0
+	if h.interval == 0 {
+		h.interval = time.Second
+	} else {
+		h.interval *= 2
+	}
+	if h.interval > maxInterval {
+		h.interval = maxInterval
+	}
+	if h.next.Before(until) {
+		h.next = until
+	}
+	return h.interval
+}
+
+// Ease counts a clean response. after easeAfterOKs of them a slowed down
+// host gets half its spacing back. true = we just sped up.
+func (l *RateLimiter) Ease(host string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	h := l.host(host)
+	if h.interval <= l.interval {
+		return false
+	}
+	h.oks++
+	if h.oks < easeAfterOKs {
+		return false
+	}
+	h.oks = 0
+	h.interval /= 2
+	if h.interval < l.interval || (l.interval == 0 && h.interval < time.Second) {
+		h.interval = l.interval
+	}
+	return true
+}
+
+// Interval is the current spacing between requests to host
+func (l *RateLimiter) Interval(host string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.host(host).interval
+}
+
+// limitDoer makes every request wait for its host's token
+type limitDoer struct {
+	lim  *RateLimiter
+	next httpDoer
+}
+
+func (d *limitDoer) Do(req *http.Request) (*http.Response, error) {
+	if err := d.lim.Wait(req.Context(), req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	return d.next.Do(req)
+}
@@ -0,0 +1,28 @@
+package config_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestRateLimiter_PerHost(t *testing.T) {
+	lim := config.NewRateLimiter(20) // 50ms apart
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() { defer wg.Done(); _ = lim.Wait(context.Background(), "a.example") }()
+		go func() { defer wg.Done(); _ = lim.Wait(context.Background(), "b.example") }()
+	}
+	wg.Wait()
+
+	// 5 per host at 20 rps = 4 gaps of 50ms, hosts run side by side
+	if took := time.Since(start); took < 200*time.Millisecond || took > 400*time.Millisecond {
+		t.Fatalf("took %v", took)
+	}
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+)
+
+// Enumeration.Redirects values
+const (
+	RedirectNone     = "none"      // record the Location, don't go there
+	RedirectSameHost = "same-host" // follow while it stays on the same host
+	RedirectFollow   = "follow"    // follow anywhere the scope allows
+)
+
+const maxRedirects = 10
+
+// RedirectHop is one request made while following a redirect
+type RedirectHop struct {
+	URL        string `json:"url"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Location   string `json:"location,omitempty"`
+	Skipped    string `json:"skipped,omitempty"` // why we didn't go there (policy, scope)
+	Error      string `json:"error,omitempty"`
+}
+
+// follow walks the redirect chain starting at from -> loc one hop at a
+// time through the full HTTP stack, so every hop is scope checked and
+// budgeted like any other request
+func (e *enumRun) follow(ctx context.Context, from *url.URL, loc string) []RedirectHop {
+	var hops []RedirectHop
+	seen := map[string]bool{from.String(): true}
+
+	cur := from
+	for len(hops) < maxRedirects {
+		next, err := cur.Parse(loc)
+		if err != nil {
+			return hops
+		}
+		hop := RedirectHop{URL: next.String()}
+
+		switch {
+		case seen[hop.URL]:
+			hop.Skipped = "redirect loop"
+		case e.redirects == RedirectSameHost && next.Hostname() != from.Hostname():
+			hop.Skipped = "other host"
+		case e.guard.PathExcluded(next.Path):
+			hop.Skipped = "excluded path"
+		}
+		if hop.Skipped != "" {
+			return append(hops, hop)
+		}
+		seen[hop.URL] = true
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, hop.URL, nil)
+		if err != nil {
+			return hops
+		}
+		resp, err := e.client.Do(req)
+		if errors.Is(err, ErrOutOfScope) {
+			hop.Skipped = "out of scope"
+			return append(hops, hop)
+		}
+		if err != nil {
+			hop.Error = err.Error()
+			return append(hops, hop)
+		}
+		readBody(resp)
+
+		hop.StatusCode = resp.StatusCode
+		hop.Location = resp.Header.Get("Location")
+		hops = append(hops, hop)
+		if hop.Location == "" || resp.StatusCode < 300 || resp.StatusCode > 399 {
+			return hops
+		}
+		cur, loc = next, hop.Location
+	}
+	return hops
+}
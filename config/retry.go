@@ -0,0 +1,63 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// first retry waits this long, every next one twice as long
+var retryBackoff = 500 * time.Millisecond
+
+// retryDoer retries flaky requests (network errors only) Limits.Retries
+// times. every attempt goes through budget and rate limits again.
+type retryDoer struct {
+	retries int
+	next    httpDoer
+}
+
+func (d *retryDoer) Do(req *http.Request) (*http.Response, error) {
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		resp, err := d.next.Do(r)
+		if err == nil || attempt >= d.retries || !retryable(req.Context(), err) {
+			if err != nil && attempt > 0 {
+				err = fmt.Errorf("after %d retries: %w", attempt, err)
+			}
+			return resp, err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// retryable: only flaky network stuff (timeouts included), our own
+// refusals and a cancelled run are final
+func retryable(ctx context.Context, err error) bool {
+	return ctx.Err() == nil &&
+		!errors.Is(err, ErrOutOfScope) &&
+		!errors.Is(err, ErrBudgetExhausted) &&
+		!errors.Is(err, ErrHostDegraded) &&
+		!errors.Is(err, ErrOutsideWindow)
+}
@@ -0,0 +1,323 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrOutOfScope is returned when a request (or a redirect hop) would land
+// outside of Scope.Targets. rules of engagement are a contract, so we refuse.
+var ErrOutOfScope = errors.New("request out of scope")
+
+// scopeSet is the whole Scope (allow + exclude lists) in matchable form
+type scopeSet struct {
+	include *hostSet
+	exclude *hostSet
+	ports   map[int]struct{} // exclude_ports
+	paths   []string         // exclude_paths, prefix match
+}
+
+func newScopeSet(scope Scope) (*scopeSet, error) {
+	include, err := scope.ParsedTargets()
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := ParseTargets(scope.ExcludeTargets)
+	if err != nil {
+		return nil, fmt.Errorf("exclude_targets: %w", err)
+	}
+
+	s := &scopeSet{
+		include: newHostSet(include),
+		exclude: newHostSet(exclude),
+		ports:   map[int]struct{}{},
+	}
+	for _, p := range scope.ExcludePorts {
+		s.ports[p] = struct{}{}
+	}
+	for _, p := range scope.ExcludePaths {
+		s.paths = append(s.paths, normalizePath(p))
+	}
+	return s, nil
+}
+
+// allowsURL reports whether u may be requested, if not it also says why
+func (s *scopeSet) allowsURL(u *url.URL) (bool, string) {
+	host := u.Hostname()
+	if !s.include.allows(host) {
+		return false, "host not in targets"
+	}
+	if s.exclude.allows(host) {
+		return false, "host excluded"
+	}
+	if _, ok := s.ports[urlPort(u)]; ok {
+		return false, "port excluded"
+	}
+	if s.pathExcluded(u.Path) {
+		return false, "path excluded"
+	}
+	return true, ""
+}
+
+// pathExcluded: "/checkout" covers "/checkout" and "/checkout/..." but not "/checkouts"
+func (s *scopeSet) pathExcluded(p string) bool {
+	p = normalizePath(p)
+	for _, ex := range s.paths {
+		if ex == "/" || p == ex || strings.HasPrefix(p, strings.TrimSuffix(ex, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func normalizePath(p string) string {
+	p = strings.TrimSpace(p)
+	if !strings.HasPrefix(p, "/") {
+		p = "/" + p
+	}
+	return p
+}
+
+// urlPort returns explicit port or the scheme default (0 if unknown)
+func urlPort(u *url.URL) int {
+	if p := u.Port(); p != "" {
+		n, _ := strconv.Atoi(p)
+		return n
+	}
+	switch u.Scheme {
+	case "http":
+		return 80
+	case "https":
+		return 443
+	}
+	return 0
+}
+
+// hostSet is a list of domains/IPs/CIDRs expanded into something we can match hosts against
+type hostSet struct {
+	hosts    map[string]struct{} // exact domains + IPs (lowercase)
+	suffixes []string            // "*.example.com" -> ".example.com"
+	nets     []*net.IPNet
+}
+
+func newHostSet(targets []Target) *hostSet {
+	s := &hostSet{hosts: map[string]struct{}{}}
+
+	for _, t := range targets {
+		switch t.Kind {
+		case TargetCIDR:
+			s.nets = append(s.nets, t.Net)
+		case TargetWildcard:
+			s.suffixes = append(s.suffixes, "."+t.Host)
+		default:
+			s.hosts[t.Host] = struct{}{}
+		}
+	}
+
+	return s
+}
+
+// allows reports whether host (no port) is covered by the scope
+func (s *hostSet) allows(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if host == "" {
+		return false
+	}
+	if _, ok := s.hosts[host]; ok {
+		return true
+	}
+	for _, suf := range s.suffixes {
+		if strings.HasSuffix(host, suf) {
+			return true
+		}
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		for _, n := range s.nets {
+			if n.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ScopeGuard wraps any httpDoer and refuses every request or redirect hop
+// that leaves Scope.Targets or hits one of the exclusions. blocked URLs are
+// logged and kept for the report.
+type ScopeGuard struct {
+	next httpDoer
+	set  *scopeSet
+
+	mu      sync.Mutex
+	blocked []string
+	vhosts  map[string]string // name -> IP it lives on, see AddVhost
+}
+
+func NewScopeGuard(scope Scope, next httpDoer) (*ScopeGuard, error) {
+	set, err := newScopeSet(scope)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		next = http.DefaultClient
+	}
+
+	g := &ScopeGuard{set: set}
+
+	// http.Client follows redirects on its own, so hook every hop
+	if client, ok := next.(*http.Client); ok {
+		c := *client
+		prev := client.CheckRedirect
+		c.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+			var perr error
+			if prev != nil {
+				// a client that doesn't follow isn't going anywhere, nothing to block
+				if perr = prev(req, via); errors.Is(perr, http.ErrUseLastResponse) {
+					return perr
+				}
+			}
+			if err := g.check(req); err != nil {
+				return err
+			}
+			if prev != nil {
+				return perr
+			}
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return nil
+		}
+		next = &c
+	}
+	g.next = next
+
+	return g, nil
+}
+
+func (g *ScopeGuard) Do(req *http.Request) (*http.Response, error) {
+	if err := g.check(req); err != nil {
+		return nil, err
+	}
+	req = g.route(req)
+
+	resp, err := g.next.Do(req)
+	if err != nil {
+		return resp, err
+	}
+
+	// doers that aren't *http.Client might still have followed something
+	if resp.Request != nil && resp.Request != req {
+		if err := g.check(resp.Request); err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+	}
+
+	return resp, nil
+}
+
+// wrap slides extra layers (budget, rate limits) between the guard and the
+// doer it protects, so only in-scope requests ever reach them
+func (g *ScopeGuard) wrap(mw func(httpDoer) httpDoer) {
+	g.next = mw(g.next)
+}
+
+// PathExcluded reports whether p falls under Scope.ExcludePaths
+func (g *ScopeGuard) PathExcluded(p string) bool {
+	return g.set.pathExcluded(p)
+}
+
+// Blocked returns every URL the guard refused so far
+func (g *ScopeGuard) Blocked() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]string(nil), g.blocked...)
+}
+
+// AddVhost makes name reachable on ip without DNS. name is in scope as long
+// as ip is and name itself isn't excluded.
+func (g *ScopeGuard) AddVhost(name, ip string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.vhosts == nil {
+		g.vhosts = map[string]string{}
+	}
+	g.vhosts[strings.ToLower(name)] = ip
+}
+
+func (g *ScopeGuard) vhostIP(name string) (string, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	ip, ok := g.vhosts[strings.ToLower(name)]
+	return ip, ok
+}
+
+// onIP is u pointed at the IP behind its vhost (u itself if it isn't one)
+func (g *ScopeGuard) onIP(u *url.URL) *url.URL {
+	ip, ok := g.vhostIP(u.Hostname())
+	if !ok {
+		return u
+	}
+	c := *u
+	c.Host = ip
+	if strings.Contains(ip, ":") {
+		c.Host = "[" + ip + "]"
+	}
+	if p := u.Port(); p != "" {
+		c.Host = net.JoinHostPort(ip, p)
+	}
+	return &c
+}
+
+// allows is check without the logging
+func (g *ScopeGuard) allows(u *url.URL) (bool, string) {
+	if _, ok := g.vhostIP(u.Hostname()); ok && g.set.exclude.allows(u.Hostname()) {
+		return false, "host excluded"
+	}
+	return g.set.allowsURL(g.onIP(u))
+}
+
+// hostOnly strips the port off a Host header
+func hostOnly(hostport string) string {
+	if h, _, err := net.SplitHostPort(hostport); err == nil {
+		return h
+	}
+	return strings.Trim(hostport, "[]")
+}
+
+// route sends requests for a vhost to its IP, with the name in Host
+func (g *ScopeGuard) route(req *http.Request) *http.Request {
+	u := g.onIP(req.URL)
+	if u == req.URL {
+		return req
+	}
+	r := req.Clone(req.Context())
+	r.URL = u
+	if r.Host == "" {
+		r.Host = req.URL.Host
+	}
+	return r
+}
+
+func (g *ScopeGuard) check(req *http.Request) error {
+	ok, why := g.allows(req.URL)
+	if ok && req.Host != "" && g.set.exclude.allows(hostOnly(req.Host)) {
+		ok, why = false, "Host header excluded" // in-scope IP, carved-out name
+	}
+	if ok {
+		return nil
+	}
+
+	g.mu.Lock()
+	g.blocked = append(g.blocked, req.URL.String())
+	g.mu.Unlock()
+
+	fmt.Printf("[!] blocked out-of-scope request to %s (%s)\n", req.URL, why)
+	return fmt.Errorf("%w: %s (%s)", ErrOutOfScope, req.URL, why)
+}
@@ -0,0 +1,87 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestScopeGuard_BlocksOutOfScope(t *testing.T) {
+	var sc config.Scope
+	sc.Targets = []string{"example.com", "*.corp.example", "10.0.0.0/24"}
+
+	g, err := config.NewScopeGuard(sc, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	for _, u := range []string{"http://evil.com/", "http://10.0.1.1/", "http://corp.example/"} {
+		req, _ := http.NewRequest(http.MethodGet, u, nil)
+		if _, err := g.Do(req); !errors.Is(err, config.ErrOutOfScope) {
+			t.Errorf("%s: got %v, wanted ErrOutOfScope", u, err)
+		}
+	}
+	if len(g.Blocked()) != 3 {
+		t.Errorf("got %v blocked", g.Blocked())
+	}
+}
+
+func TestScopeGuard_BlocksRedirectHop(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin" {
+			http.NotFound(w, r)
+			return
+		}
+		http.Redirect(w, r, "http://evil.example/steal", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.HTTP = srv.Client()
+
+	out, err := rz.Enum(context.Background(), []string{"admin"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(out) != 0 {
+		t.Fatalf("got %#v", out)
+	}
+
+	g, _ := rz.Guard()
+	if b := g.Blocked(); len(b) != 1 || b[0] != "http://evil.example/steal" {
+		t.Fatalf("got blocked %v", b)
+	}
+}
+
+func TestScopeGuard_Exclusions(t *testing.T) {
+	var sc config.Scope
+	sc.Targets = []string{"10.0.0.0/24"}
+	sc.ExcludeTargets = []string{"10.0.0.66"}
+	sc.ExcludePorts = []int{8443}
+	sc.ExcludePaths = []string{"/checkout"}
+
+	g, err := config.NewScopeGuard(sc, http.DefaultClient)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	for _, u := range []string{
+		"http://10.0.0.66/",
+		"https://10.0.0.1:8443/",
+		"http://10.0.0.1/checkout",
+		"http://10.0.0.1/checkout/pay",
+	} {
+		req, _ := http.NewRequest(http.MethodGet, u, nil)
+		if _, err := g.Do(req); !errors.Is(err, config.ErrOutOfScope) {
+			t.Errorf("%s: got %v, wanted ErrOutOfScope", u, err)
+		}
+	}
+	if g.PathExcluded("/checkouts") {
+		t.Errorf("/checkouts should not be excluded")
+	}
+}
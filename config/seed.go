@@ -0,0 +1,287 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	maxSitemaps     = 20   // sitemap files fetched per host, indexes included
+	maxSitemapDepth = 3    // index -> index -> ... -> urlset
+	maxSeeds        = 1000 // paths seeded per base URL
+)
+
+// security.txt fields that point at URLs
+var securityTxtLinks = []string{"contact", "policy", "acknowledgments", "hiring", "encryption", "canonical"}
+
+// Seed reads robots.txt, sitemap.xml (indexes and .gz too) and security.txt
+// on every web target. in-scope paths they mention get enumerated by the
+// next Enum call, disallowed robots.txt paths come back as findings.
+func (cfg *Razor) Seed(ctx context.Context) ([]Finding, error) {
+	guard, err := cfg.Guard()
+	if err != nil {
+		return nil, err
+	}
+	client, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+	bases, err := cfg.WebTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	var findings []Finding
+	for _, base := range bases {
+		if ctx.Err() != nil {
+			return findings, ctx.Err()
+		}
+		u, err := url.Parse(base)
+		if err != nil || !cfg.inFocus(u.Hostname()) {
+			continue
+		}
+
+		s := &seeder{guard: guard, client: client, base: u, seen: map[string]bool{}}
+		findings = append(findings, s.robots(ctx)...)
+		s.sitemap(ctx, JoinURL(s.root(), "sitemap.xml"), 0)
+		findings = append(findings, s.securityTxt(ctx)...)
+		if s.closed {
+			return findings, ErrOutsideWindow
+		}
+
+		if len(s.paths) > 0 {
+			fmt.Printf("[~] %d paths seeded from robots/sitemap/security.txt on %s\n", len(s.paths), base)
+			cfg.addSeeds(base, s.paths)
+		}
+	}
+	return findings, nil
+}
+
+// addSeeds queues extra words for base's first Enum level
+func (cfg *Razor) addSeeds(base string, words []string) {
+	if len(words) == 0 {
+		return
+	}
+	if cfg.seeds == nil {
+		cfg.seeds = map[string][]string{}
+	}
+	cfg.seeds[base] = NormalizeWords(append(cfg.seeds[base], words...))
+}
+
+// seeder is one base URL's trip through its metadata files
+type seeder struct {
+	guard  *ScopeGuard
+	client httpDoer
+	base   *url.URL
+
+	paths    []string        // relative to base
+	seen     map[string]bool // sitemaps already fetched
+	sitemaps int
+	closed   bool // time window is over, nothing more goes out
+}
+
+func (s *seeder) root() string {
+	return fmt.Sprintf("%s://%s/", s.base.Scheme, s.base.Host)
+}
+
+// get fetches rawURL, nil unless it's a 200
+func (s *seeder) get(ctx context.Context, rawURL string) []byte {
+	if s.closed {
+		return nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil || s.guard.PathExcluded(req.URL.Path) {
+		return nil
+	}
+	resp, err := s.client.Do(req)
+	if errors.Is(err, ErrOutsideWindow) {
+		s.closed = true
+		return nil
+	}
+	if err != nil {
+		if !errors.Is(err, ErrOutOfScope) && ctx.Err() == nil {
+			fmt.Printf("[!] %s: %v\n", rawURL, err)
+		}
+		return nil
+	}
+	body := readBody(resp)
+	if resp.StatusCode != http.StatusOK {
+		return nil
+	}
+	return body
+}
+
+// add seeds ref (absolute URL or path) when it's on our host, under the
+// base path and in scope. returns the path relative to base.
+func (s *seeder) add(ref string) (string, bool) {
+	u, err := s.base.Parse(ref)
+	if err != nil || u.Host != s.base.Host {
+		return "", false
+	}
+	if ok, _ := s.guard.allows(u); !ok {
+		return "", false
+	}
+	rel, ok := strings.CutPrefix(u.Path, strings.TrimSuffix(s.base.Path, "/"))
+	if !ok || strings.Trim(rel, "/") == "" {
+		return "", false
+	}
+	if len(s.paths) < maxSeeds {
+		s.paths = append(s.paths, rel)
+	}
+	return rel, true
+}
+
+// robots seeds Allow/Disallow paths, follows Sitemap lines and reports
+// every disallowed path
+func (s *seeder) robots(ctx context.Context) []Finding {
+	robotsURL := JoinURL(s.root(), "robots.txt")
+	body := s.get(ctx, robotsURL)
+	if body == nil {
+		return nil
+	}
+
+	var findings []Finding
+	var sitemaps []string
+	sc := bufio.NewScanner(bytes.NewReader(body))
+	for sc.Scan() {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		key, val, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		val = strings.TrimSpace(val)
+
+		switch key {
+		case "sitemap":
+			sitemaps = append(sitemaps, val)
+		case "allow", "disallow":
+			// wildcards: keep what's before them, that part is a real path
+			if i := strings.IndexAny(val, "*$"); i >= 0 {
+				val = val[:i]
+			}
+			if !strings.HasPrefix(val, "/") {
+				continue
+			}
+			rel, ok := s.add(val)
+			if ok && key == "disallow" {
+				findings = append(findings, Finding{
+					Host:     s.base.Hostname(),
+					Endpoint: JoinURL(s.base.String(), rel),
+					Title:    "Path disallowed in robots.txt",
+					Severity: SevInfo,
+					Detail:   fmt.Sprintf("%s lists %q under Disallow", robotsURL, val),
+				})
+			}
+		}
+	}
+
+	for _, sm := range sitemaps {
+		s.sitemap(ctx, sm, 0)
+	}
+	return findings
+}
+
+type sitemapXML struct {
+	XMLName xml.Name
+	URLs    []struct {
+		Loc string `xml:"loc"`
+	} `xml:"url"`
+	Sitemaps []struct {
+		Loc string `xml:"loc"`
+	} `xml:"sitemap"`
+}
+
+// sitemap seeds every <loc> of a urlset and walks sitemap indexes
+func (s *seeder) sitemap(ctx context.Context, rawURL string, depth int) {
+	u, err := s.base.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.Host != s.base.Host || depth > maxSitemapDepth ||
+		s.seen[u.String()] || s.sitemaps >= maxSitemaps {
+		return
+	}
+	s.seen[u.String()] = true
+	s.sitemaps++
+
+	body := s.get(ctx, u.String())
+	if body == nil {
+		return
+	}
+	if len(body) > 2 && body[0] == 0x1f && body[1] == 0x8b {
+		if body, err = gunzip(body); err != nil {
+			fmt.Printf("[!] %s: %v\n", u, err)
+			return
+		}
+	}
+
+	var sm sitemapXML
+	if err := xml.Unmarshal(body, &sm); err != nil {
+		return // soft-404s and html end up here
+	}
+	for _, loc := range sm.URLs {
+		s.add(strings.TrimSpace(loc.Loc))
+	}
+	for _, loc := range sm.Sitemaps {
+		s.sitemap(ctx, loc.Loc, depth+1)
+	}
+}
+
+func gunzip(b []byte) ([]byte, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	return io.ReadAll(io.LimitReader(zr, maxBody))
+}
+
+// securityTxt seeds the on-host links in security.txt and reports the
+// contacts it lists
+func (s *seeder) securityTxt(ctx context.Context) []Finding {
+	for _, p := range []string{".well-known/security.txt", "security.txt"} {
+		txtURL := JoinURL(s.root(), p)
+		body := s.get(ctx, txtURL)
+		if body == nil {
+			continue
+		}
+
+		var contacts []string
+		sc := bufio.NewScanner(bytes.NewReader(body))
+		for sc.Scan() {
+			key, val, ok := strings.Cut(sc.Text(), ":")
+			if !ok || strings.HasPrefix(key, "#") {
+				continue
+			}
+			key = strings.ToLower(strings.TrimSpace(key))
+			val = strings.TrimSpace(val)
+			if key == "contact" {
+				contacts = append(contacts, val)
+			}
+			for _, link := range securityTxtLinks {
+				if key == link {
+					s.add(val)
+				}
+			}
+		}
+		if len(contacts) == 0 {
+			continue // a soft-404 with a colon in it, not a security.txt
+		}
+
+		return []Finding{{
+			Host:     s.base.Hostname(),
+			Endpoint: txtURL,
+			Title:    "security.txt present",
+			Severity: SevInfo,
+			Detail:   "contacts: " + strings.Join(contacts, ", "),
+		}}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package config_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestSeed(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	fmt.Fprint(zw, `<?xml version="1.0"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>/blog/post-1</loc></url>
+  <url><loc>http://elsewhere.example/nope</loc></url>
+</urlset>`)
+	zw.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprint(w, "User-agent: *\nDisallow: /secret/ # shh\nDisallow: /*.bak$\nAllow: /public\nSitemap: /sitemap-index.xml\n")
+		case "/sitemap-index.xml":
+			fmt.Fprint(w, `<sitemapindex><sitemap><loc>/sitemap-1.xml.gz</loc></sitemap></sitemapindex>`)
+		case "/sitemap-1.xml.gz":
+			w.Write(gz.Bytes())
+		case "/.well-known/security.txt":
+			fmt.Fprint(w, "Contact: mailto:sec@example.com\nPolicy: /disclosure\n")
+		case "/secret/", "/blog/post-1", "/disclosure":
+			fmt.Fprint(w, r.URL.Path)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.HTTP = srv.Client()
+
+	findings, err := rz.Seed(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(findings) != 2 || findings[0].Endpoint != srv.URL+"/secret/" ||
+		!strings.Contains(findings[1].Detail, "sec@example.com") {
+		t.Fatalf("got findings %#v", findings)
+	}
+
+	out, err := rz.Enum(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	var got []string
+	for _, r := range out {
+		got = append(got, strings.TrimPrefix(r.Endpoint, srv.URL))
+	}
+	want := []string{"/blog/post-1", "/disclosure", "/secret/"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
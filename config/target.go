@@ -0,0 +1,183 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type TargetKind int
+
+const (
+	TargetHost     TargetKind = iota // single domain or IP
+	TargetCIDR                       // "10.0.0.0/24"
+	TargetWildcard                   // "*.example.com", scope only, nothing to scan directly
+)
+
+// Target is one parsed entry of Scope.Targets. every module derives what it
+// needs from this instead of poking at the raw string.
+type Target struct {
+	Raw      string
+	Kind     TargetKind
+	Scheme   string // "" unless the target was given as URL
+	Host     string // domain/IP, wildcard without "*.", network for CIDR
+	Port     int    // 0 = not specified
+	BasePath string // always starts and ends with "/"
+	Net      *net.IPNet
+}
+
+func ParseTarget(raw string) (Target, error) {
+	t := Target{Raw: raw, BasePath: "/"}
+	s := strings.TrimSpace(raw) // only scheme and host are case-insensitive, paths aren't
+	if s == "" {
+		return t, fmt.Errorf("empty target")
+	}
+
+	// CIDR first, "10.0.0.0/24" would otherwise look like a path
+	if _, ipNet, err := net.ParseCIDR(s); err == nil {
+		t.Kind = TargetCIDR
+		t.Host = ipNet.String()
+		t.Net = ipNet
+		return t, nil
+	}
+
+	host := s
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil || u.Hostname() == "" {
+			return t, fmt.Errorf("invalid target %q", raw)
+		}
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return t, fmt.Errorf("invalid target %q: unsupported scheme %q", raw, u.Scheme)
+		}
+		t.Scheme = u.Scheme // url.Parse lowercases it
+		host = strings.ToLower(u.Host)
+		if u.Path != "" {
+			t.BasePath = strings.TrimSuffix(normalizePath(u.Path), "/") + "/"
+		}
+	} else if strings.Contains(s, "/") {
+		return t, fmt.Errorf("invalid target %q: paths need a scheme (http:// or https://)", raw)
+	} else {
+		host = strings.ToLower(s)
+	}
+
+	if h, p, err := net.SplitHostPort(host); err == nil {
+		port, err := strconv.Atoi(p)
+		if err != nil || port < 1 || port > 65535 {
+			return t, fmt.Errorf("invalid target %q: bad port %q", raw, p)
+		}
+		host, t.Port = h, port
+	}
+	host = strings.Trim(host, "[]")
+
+	if strings.HasPrefix(host, "*.") {
+		t.Kind = TargetWildcard
+		t.Host = host[2:]
+		return t, nil
+	}
+	if host == "" || strings.ContainsAny(host, "*/ ") {
+		return t, fmt.Errorf("invalid target %q", raw)
+	}
+
+	t.Kind = TargetHost
+	t.Host = host
+	return t, nil
+}
+
+func ParseTargets(raw []string) ([]Target, error) {
+	targets := make([]Target, 0, len(raw))
+	for _, r := range raw {
+		if strings.TrimSpace(r) == "" {
+			continue
+		}
+		t, err := ParseTarget(r)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+	return targets, nil
+}
+
+// NmapTarget is what we hand to nmap ("" for wildcards, nmap can't expand those)
+func (t Target) NmapTarget() string {
+	if t.Kind == TargetWildcard {
+		return ""
+	}
+	return t.Host
+}
+
+// BaseURLs are the web roots for this target. bare hosts get both http and
+// https, CIDRs and wildcards get none (nmap results fill those in).
+func (t Target) BaseURLs() []string {
+	if t.Kind != TargetHost {
+		return nil
+	}
+
+	schemes := []string{"http", "https"}
+	if t.Scheme != "" {
+		schemes = []string{t.Scheme}
+	} else if t.Port == 443 {
+		schemes = []string{"https"}
+	} else if t.Port == 80 {
+		schemes = []string{"http"}
+	}
+
+	host := t.Host
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6
+	}
+	if t.Port != 0 {
+		host = net.JoinHostPort(t.Host, strconv.Itoa(t.Port))
+	}
+
+	urls := make([]string, 0, len(schemes))
+	for _, s := range schemes {
+		urls = append(urls, fmt.Sprintf("%s://%s%s", s, host, t.BasePath))
+	}
+	return urls
+}
+
+// JoinURL glues a base URL and a wordlist entry with exactly one '/' in between
+func JoinURL(base, p string) string {
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(p, "/")
+}
+
+// ParsedTargets returns Scope.Parsed, parsing Scope.Targets on the fly when
+// the scope wasn't produced by Load().
+func (s *Scope) ParsedTargets() ([]Target, error) {
+	if s.Parsed != nil {
+		return s.Parsed, nil
+	}
+	return ParseTargets(s.Targets)
+}
+
+// Hosts lists targets in nmap syntax, without the exclusions
+func (s *Scope) Hosts() ([]string, error) {
+	targets, err := s.ParsedTargets()
+	if err != nil {
+		return nil, err
+	}
+	var hosts []string
+	for _, t := range targets {
+		if h := t.NmapTarget(); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts, nil
+}
+
+// BaseURLs lists every web root derived from the targets
+func (s *Scope) BaseURLs() ([]string, error) {
+	targets, err := s.ParsedTargets()
+	if err != nil {
+		return nil, err
+	}
+	var urls []string
+	for _, t := range targets {
+		urls = append(urls, t.BaseURLs()...)
+	}
+	return urls, nil
+}
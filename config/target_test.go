@@ -0,0 +1,53 @@
+package config_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestParseTarget(t *testing.T) {
+	testCases := []struct {
+		raw  string
+		kind config.TargetKind
+		host string
+		urls []string
+	}{
+		{"example.com", config.TargetHost, "example.com", []string{"http://example.com/", "https://example.com/"}},
+		{"https://x.com/app", config.TargetHost, "x.com", []string{"https://x.com/app/"}},
+		{"HTTPS://Example.COM/MyApp/", config.TargetHost, "example.com", []string{"https://example.com/MyApp/"}},
+		{"Example.COM:80", config.TargetHost, "example.com", []string{"http://example.com:80/"}},
+		{"10.0.0.5:443", config.TargetHost, "10.0.0.5", []string{"https://10.0.0.5:443/"}},
+		{"10.0.0.0/24", config.TargetCIDR, "10.0.0.0/24", nil},
+		{"*.corp.example", config.TargetWildcard, "corp.example", nil},
+	}
+
+	for _, tc := range testCases {
+		got, err := config.ParseTarget(tc.raw)
+		if err != nil {
+			t.Fatalf("%s: unexpected err: %v", tc.raw, err)
+		}
+		if got.Kind != tc.kind || got.Host != tc.host {
+			t.Errorf("%s: got %+v", tc.raw, got)
+		}
+		if urls := got.BaseURLs(); !reflect.DeepEqual(urls, tc.urls) {
+			t.Errorf("%s: got urls %v but wanted %v", tc.raw, urls, tc.urls)
+		}
+	}
+
+	for _, bad := range []string{"ftp://x.com", "x.com/admin", "x.com:99999"} {
+		if _, err := config.ParseTarget(bad); err == nil {
+			t.Errorf("%s: wanted error", bad)
+		}
+	}
+}
+
+func TestJoinURL(t *testing.T) {
+	if got := config.JoinURL("https://x/", "/admin"); got != "https://x/admin" {
+		t.Errorf("got %s", got)
+	}
+	if got := config.JoinURL("https://x/app", "api/"); got != "https://x/app/api/" {
+		t.Errorf("got %s", got)
+	}
+}
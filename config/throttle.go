@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// how many times a throttled request is sent again (after backing off)
+const throttleRetries = 3
+
+// how much of a suspicious body we read looking for a WAF block page
+const wafSniffBytes = 64 << 10
+
+// wafMarkers are bits of the usual WAF/CDN block pages (lowercase)
+var wafMarkers = []string{
+	"attention required! | cloudflare",
+	"cf-error-details",
+	"access denied | ",
+	"reference #", // akamai
+	"request unsuccessful. incapsula",
+	"the requested url was rejected", // f5 asm
+	"sucuri website firewall",
+	"mod_security",
+	"modsecurity",
+	"blocked by the web application firewall",
+	"aws waf",
+}
+
+// throttleDoer watches for a host pushing back (429/503, Retry-After, WAF
+// block pages), slows that host down in the limiter and tries again.
+type throttleDoer struct {
+	lim  *RateLimiter
+	log  *EventLog
+	next httpDoer
+}
+
+func (d *throttleDoer) Do(req *http.Request) (*http.Response, error) {
+	host := req.URL.Hostname()
+
+	for attempt := 0; ; attempt++ {
+		r := req
+		if attempt > 0 {
+			r = req.Clone(req.Context())
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				r.Body = body
+			}
+		}
+
+		resp, err := d.next.Do(r)
+		if err != nil {
+			return resp, err
+		}
+
+		why, wait := throttled(resp)
+		if why == "" {
+			if d.lim.Ease(host) {
+				d.log.Add(host, EventRecover, "responses look clean again, spacing now %v", d.lim.Interval(host))
+			}
+			return resp, nil
+		}
+
+		interval := d.lim.Backoff(host, time.Now().Add(wait))
+		d.log.Add(host, EventBackoff, "%s on %s, slowing down to 1 request per %v", why, req.URL.Path, interval)
+
+		if attempt >= throttleRetries {
+			return resp, nil
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}
+}
+
+// throttled says why resp looks like the host pushing back ("" = it doesn't)
+// and how long it asked us to wait. the body stays readable for the caller.
+func throttled(resp *http.Response) (string, time.Duration) {
+	wait := retryAfter(resp.Header.Get("Retry-After"))
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return "429 too many requests", wait
+	case http.StatusServiceUnavailable:
+		return "503 service unavailable", wait
+	case http.StatusForbidden, http.StatusNotAcceptable:
+	default:
+		return "", 0
+	}
+
+	// 403/406 are only throttling if it's a block page
+	head, _ := io.ReadAll(io.LimitReader(resp.Body, wafSniffBytes))
+	resp.Body = readCloser{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+
+	body := strings.ToLower(string(head))
+	for _, m := range wafMarkers {
+		if strings.Contains(body, m) {
+			return "WAF block page (" + m + ")", wait
+		}
+	}
+	return "", 0
+}
+
+// retryAfter understands both "120" and an HTTP date
+func retryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if s, err := strconv.Atoi(v); err == nil && s > 0 {
+		return min(time.Duration(s)*time.Second, maxInterval)
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return min(max(time.Until(t), 0), maxInterval)
+	}
+	return 0
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,78 @@
+package config_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestEnum_BacksOffOn429(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/admin" { // calibration
+			http.NotFound(w, r)
+			return
+		}
+		if hits.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.HTTP = srv.Client()
+
+	start := time.Now()
+	out, err := rz.Enum(context.Background(), []string{"admin"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(out) != 1 || out[0].StatusCode != http.StatusOK {
+		t.Fatalf("got %#v", out)
+	}
+	if time.Since(start) < time.Second {
+		t.Fatalf("didn't honour Retry-After")
+	}
+
+	events := rz.Events()
+	if len(events) != 1 || events[0].Kind != config.EventBackoff {
+		t.Fatalf("got events %+v", events)
+	}
+}
+
+func TestThrottle_RetriesPayBudget(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Limits.TotalRequestsPerHost = 1
+	rz.HTTP = srv.Client()
+
+	guard, err := rz.Guard()
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+	if resp, err := guard.Do(req); err == nil {
+		resp.Body.Close()
+	}
+
+	budget, _ := rz.Budget()
+	if hits.Load() != 1 || budget.Usage()[0].Used != 1 {
+		t.Fatalf("%d requests sent, ledger says %+v", hits.Load(), budget.Usage())
+	}
+}
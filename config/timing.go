@@ -0,0 +1,30 @@
+package config
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type elapsedKey struct{}
+
+// withElapsed returns a ctx that the bottom of the HTTP stack fills with
+// how long the request really took on the wire (no rate limit waits)
+func withElapsed(ctx context.Context) (context.Context, *time.Duration) {
+	d := new(time.Duration)
+	return context.WithValue(ctx, elapsedKey{}, d), d
+}
+
+// timeDoer sits right above cfg.HTTP and times every attempt
+type timeDoer struct {
+	next httpDoer
+}
+
+func (d *timeDoer) Do(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := d.next.Do(req)
+	if p, ok := req.Context().Value(elapsedKey{}).(*time.Duration); ok {
+		*p = time.Since(start)
+	}
+	return resp, err
+}
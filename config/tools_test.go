@@ -0,0 +1,99 @@
+//go:build unix
+
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+// fakeTool puts a name on PATH that logs its args, one run per line
+func fakeTool(t *testing.T, name string) func() []string {
+	dir := t.TempDir()
+	log := filepath.Join(dir, name+".log")
+	script := "#!/bin/sh\necho \"$@\" >> " + log + "\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	return func() []string {
+		b, _ := os.ReadFile(log)
+		return strings.Split(strings.TrimSpace(string(b)), "\n")
+	}
+}
+
+func TestSQLiScan_RespectsExcludePaths(t *testing.T) {
+	runs := fakeTool(t, "sqlmap")
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{"http://shop.example/", "http://pay.example/checkout/"}
+	rz.Scope.ExcludePaths = []string{"/checkout", "/admin/"}
+
+	if err := rz.SQLiScan(context.Background()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	got := runs()
+	if len(got) != 1 || !strings.Contains(got[0], "-u http://shop.example/") {
+		t.Fatalf("got runs %q", got)
+	}
+	if !strings.Contains(got[0], `--crawl-exclude=^[a-z]+://[^/]+(?:/checkout|/admin)(?:[/?#]|$)`) {
+		t.Errorf("no crawl exclusion in %q", got[0])
+	}
+}
+
+func TestXssScan_OneRunPerService(t *testing.T) {
+	runs := fakeTool(t, "xsstrike")
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{"example.com", "https://example.com:443/", "http://example.com/", "other.example"}
+	if err := rz.AddWebTargets([]string{"https://example.com/"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rz.XssScan(context.Background()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	want := []string{"-u https://example.com/", "-u http://example.com/", "-u http://other.example/"}
+	if got := runs(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("got runs %q", got)
+	}
+}
+
+func TestXssScan_SkipsRoutedVhosts(t *testing.T) {
+	runs := fakeTool(t, "xsstrike")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Host, "dev.corp.example") {
+			fmt.Fprint(w, "<title>dev portal</title>")
+			return
+		}
+		fmt.Fprint(w, "<title>default</title>")
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL, "*.corp.example"}
+	rz.Enumeration.Vhosts = []string{"dev"}
+	rz.HTTP = srv.Client()
+
+	if f, err := rz.Vhosts(context.Background()); err != nil || len(f) != 1 {
+		t.Fatalf("vhost setup: %v %#v", err, f)
+	}
+	if err := rz.XssScan(context.Background()); err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if got := runs(); len(got) != 1 || got[0] != "-u "+srv.URL+"/" {
+		t.Fatalf("got runs %q", got)
+	}
+}
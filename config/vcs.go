@@ -0,0 +1,514 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// objects we pull per repo at most when reconstructing
+const maxRepoObjects = 500
+
+var (
+	shaRe       = regexp.MustCompile(`^[0-9a-f]{40}$`)
+	hgFeatureRe = regexp.MustCompile(`^[a-z0-9-]+$`)
+	remoteURLRe = regexp.MustCompile(`(?:https?|svn(?:\+ssh)?|ssh|git)://[^\s\x00'"<>]+`)
+)
+
+// repo dirs we know how to check, by the path segment that gives them away
+var vcsDirs = []string{".git", ".svn", ".hg"}
+
+// Repos follows up on every Enum hit inside a .git/.svn/.hg dir: it checks
+// the repo is really there (HEAD, config, index and friends) and reports the
+// remotes it gives away. with Scope.AllowIntrusive the repo gets pulled
+// into dir (git: the object tree from HEAD plus everything in the index).
+func (cfg *Razor) Repos(ctx context.Context, endpoints []DirEnumRes, dir string) ([]Finding, error) {
+	client, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+
+	roots := map[string]string{} // repo url -> kind
+	for _, e := range endpoints {
+		if e.Failed() {
+			continue
+		}
+		u, err := url.Parse(e.Endpoint)
+		if err != nil || !cfg.inFocus(u.Hostname()) {
+			continue
+		}
+		if root, kind := vcsRoot(u); root != "" {
+			roots[root] = kind
+		}
+	}
+
+	var sorted []string
+	for root := range roots {
+		sorted = append(sorted, root)
+	}
+	sort.Strings(sorted)
+
+	var findings []Finding
+	for _, root := range sorted {
+		if ctx.Err() != nil {
+			return findings, ctx.Err()
+		}
+		r := &repo{client: client, root: root}
+
+		var f *Finding
+		switch roots[root] {
+		case ".git":
+			f = r.git(ctx)
+		case ".svn":
+			f = r.svn(ctx)
+		case ".hg":
+			f = r.hg(ctx)
+		}
+		if f == nil {
+			if r.closed {
+				return findings, ErrOutsideWindow
+			}
+			continue
+		}
+
+		if cfg.Scope.AllowIntrusive {
+			dest := filepath.Join(dir, repoDirName(root))
+			n, err := r.dump(ctx, roots[root], dest)
+			if err != nil {
+				fmt.Printf("[!] reconstructing %s: %v\n", root, err)
+			}
+			if n > 0 {
+				f.Detail += fmt.Sprintf("; %d files pulled into %s", n, dest)
+			}
+		}
+		findings = append(findings, *f)
+		if r.closed {
+			return findings, ErrOutsideWindow
+		}
+	}
+	return findings, nil
+}
+
+// vcsRoot cuts u down to the repo dir it's in ("" = not in one)
+func vcsRoot(u *url.URL) (string, string) {
+	segs := strings.Split(u.Path, "/")
+	for i, s := range segs {
+		for _, kind := range vcsDirs {
+			if s == kind {
+				root := *u
+				root.Path = strings.Join(segs[:i+1], "/") + "/"
+				root.RawQuery, root.Fragment = "", ""
+				return root.String(), kind
+			}
+		}
+	}
+	return "", ""
+}
+
+// repoDirName turns a repo url into something we can mkdir
+func repoDirName(root string) string {
+	var b strings.Builder
+	for _, r := range strings.TrimPrefix(strings.TrimPrefix(root, "http://"), "https://") {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '.':
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
+
+// repo is one exposed repository and whatever we learned about it
+type repo struct {
+	client httpDoer
+	root   string // ends with "/"
+	host   string
+
+	files  map[string][]byte // raw files fetched so far, relative to root
+	index  []indexEntry
+	head   string // commit HEAD points to
+	closed bool   // time window is over, nothing more goes out
+}
+
+func (r *repo) get(ctx context.Context, p string) ([]byte, error) {
+	if r.closed {
+		return nil, ErrOutsideWindow
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, JoinURL(r.root, p), nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.host == "" {
+		r.host = req.URL.Hostname()
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		r.closed = errors.Is(err, ErrOutsideWindow)
+		return nil, err
+	}
+	body := readBody(resp)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: %s", p, resp.Status)
+	}
+	if r.files == nil {
+		r.files = map[string][]byte{}
+	}
+	r.files[p] = body
+	return body, nil
+}
+
+// finding builds the report line, remotes with credentials make it critical
+func (r *repo) finding(kind, detail string, remotes []string) *Finding {
+	sev := SevHigh
+	var shown []string
+	for _, rem := range remotes {
+		if u, err := url.Parse(rem); err == nil && u.User != nil {
+			if _, ok := u.User.Password(); ok {
+				sev = SevCritical
+				u.User = url.UserPassword(u.User.Username(), "****")
+				rem = u.String() + " (credentials in url)"
+			}
+		}
+		shown = append(shown, rem)
+	}
+	if len(shown) > 0 {
+		detail += "; remotes: " + strings.Join(shown, ", ")
+	}
+	return &Finding{
+		Host:     r.host,
+		Endpoint: r.root,
+		Title:    "Exposed " + kind + " repository",
+		Severity: sev,
+		Detail:   detail,
+	}
+}
+
+// git: HEAD has to look like HEAD, plus a config with [core] or a real index
+func (r *repo) git(ctx context.Context) *Finding {
+	head, err := r.get(ctx, "HEAD")
+	if err != nil {
+		return nil
+	}
+	ref := strings.TrimSpace(string(head))
+	if !strings.HasPrefix(ref, "ref: refs/") && !shaRe.MatchString(ref) {
+		return nil
+	}
+
+	var remotes []string
+	config, _ := r.get(ctx, "config")
+	hasConfig := bytes.Contains(config, []byte("[core]"))
+	if hasConfig {
+		remotes = gitRemotes(config)
+	}
+
+	index, _ := r.get(ctx, "index")
+	entries, err := parseGitIndex(index)
+	if err == nil {
+		r.index = entries
+	}
+	if !hasConfig && err != nil {
+		return nil // a HEAD-looking soft-404 isn't enough
+	}
+
+	r.head = r.resolve(ctx, ref)
+	detail := "HEAD: " + ref
+	if r.head != "" && r.head != ref {
+		detail += " (" + r.head + ")"
+	}
+	if err == nil {
+		detail += fmt.Sprintf("; %d files in index", len(entries))
+	}
+	return r.finding("git", detail, remotes)
+}
+
+// resolve turns HEAD into a commit sha ("" = couldn't)
+func (r *repo) resolve(ctx context.Context, ref string) string {
+	if shaRe.MatchString(ref) {
+		return ref
+	}
+	name := strings.TrimPrefix(ref, "ref: ")
+	if b, err := r.get(ctx, name); err == nil {
+		if sha := strings.TrimSpace(string(b)); shaRe.MatchString(sha) {
+			return sha
+		}
+	}
+	packed, err := r.get(ctx, "packed-refs")
+	if err != nil {
+		return ""
+	}
+	sc := bufio.NewScanner(bytes.NewReader(packed))
+	for sc.Scan() {
+		sha, n, ok := strings.Cut(sc.Text(), " ")
+		if ok && n == name && shaRe.MatchString(sha) {
+			return sha
+		}
+	}
+	return ""
+}
+
+// gitRemotes pulls every url = ... out of a git config
+func gitRemotes(config []byte) []string {
+	var remotes []string
+	sc := bufio.NewScanner(bytes.NewReader(config))
+	for sc.Scan() {
+		key, val, ok := strings.Cut(sc.Text(), "=")
+		if ok && strings.TrimSpace(key) == "url" {
+			remotes = append(remotes, strings.TrimSpace(val))
+		}
+	}
+	return remotes
+}
+
+type indexEntry struct {
+	sha  string
+	path string
+}
+
+// parseGitIndex reads a DIRC file (v2-v4). v4 compresses paths, so there
+// we only get the shas.
+func parseGitIndex(b []byte) ([]indexEntry, error) {
+	if len(b) < 12 || string(b[:4]) != "DIRC" {
+		return nil, errors.New("not a git index")
+	}
+	version := binary.BigEndian.Uint32(b[4:8])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unknown index version %d", version)
+	}
+	n := int(binary.BigEndian.Uint32(b[8:12]))
+
+	entries := make([]indexEntry, 0, min(n, 1<<16))
+	off := 12
+	for i := 0; i < n; i++ {
+		if off+62 > len(b) {
+			return entries, errors.New("truncated index")
+		}
+		e := indexEntry{sha: hex.EncodeToString(b[off+40 : off+60])}
+		flags := binary.BigEndian.Uint16(b[off+60 : off+62])
+		start := off + 62
+		if flags&0x4000 != 0 { // extended flags (v3+)
+			start += 2
+		}
+		end := bytes.IndexByte(b[start:], 0)
+		if end < 0 {
+			return entries, errors.New("truncated index")
+		}
+		if version < 4 {
+			e.path = string(b[start : start+end])
+			off += (start - off + end + 8) &^ 7 // entries are padded to 8 bytes
+		} else {
+			off = start + end + 1
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// svn: wc.db (1.7+) or the old entries file
+func (r *repo) svn(ctx context.Context) *Finding {
+	if db, err := r.get(ctx, "wc.db"); err == nil && bytes.HasPrefix(db, []byte("SQLite format 3\x00")) {
+		return r.finding("svn", "wc.db (svn 1.7+) readable", uniq(remoteURLRe.FindAllString(string(db), -1)))
+	}
+	entries, err := r.get(ctx, "entries")
+	if err != nil {
+		return nil
+	}
+	first, _, _ := strings.Cut(string(entries), "\n")
+	if first != "8" && first != "9" && first != "10" {
+		return nil
+	}
+	return r.finding("svn", "entries (svn <= 1.6) readable", uniq(remoteURLRe.FindAllString(string(entries), -1)))
+}
+
+// hg: requires lists repo features, hgrc has the paths
+func (r *repo) hg(ctx context.Context) *Finding {
+	requires, err := r.get(ctx, "requires")
+	if err != nil || !hgRequires(requires) {
+		return nil
+	}
+	var remotes []string
+	if hgrc, err := r.get(ctx, "hgrc"); err == nil {
+		remotes = uniq(remoteURLRe.FindAllString(string(hgrc), -1))
+	}
+	_, _ = r.get(ctx, "dirstate")
+	return r.finding("hg", "requires: "+strings.Join(strings.Fields(string(requires)), ", "), remotes)
+}
+
+// hgRequires: one lowercase feature per line, revlogv1 or store among them
+func hgRequires(b []byte) bool {
+	features := strings.Fields(string(b))
+	for _, f := range features {
+		if !hgFeatureRe.MatchString(f) {
+			return false
+		}
+	}
+	return contains(features, "revlogv1") || contains(features, "store")
+}
+
+func uniq(list []string) []string {
+	var out []string
+	for _, s := range list {
+		if !contains(out, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// dump writes the repo into dest: every raw file fetched goes to dest/_raw,
+// for git the blobs reachable from HEAD and the index get checked out into
+// dest/checkout. neither is a .git dir, so nothing in there runs the
+// target's config (core.fsmonitor and friends) when someone pokes at it.
+func (r *repo) dump(ctx context.Context, kind, dest string) (int, error) {
+	if kind == ".git" {
+		r.pullGit(ctx, filepath.Join(dest, "checkout"))
+	}
+
+	written := 0
+	for p, body := range r.files {
+		if err := writeUnder(filepath.Join(dest, "_raw"), p, body); err != nil {
+			return written, err
+		}
+		written++
+	}
+	return written, nil
+}
+
+// pullGit fetches loose objects: HEAD's commit, its trees and blobs, and
+// every blob in the index. blobs get checked out under dest as well, except
+// for paths that would recreate a repo dir.
+func (r *repo) pullGit(ctx context.Context, dest string) {
+	paths := map[string]string{} // blob sha -> where it lives in the tree
+	for _, e := range r.index {
+		if e.path != "" {
+			paths[e.sha] = e.path
+		}
+	}
+
+	type todo struct{ sha, path string }
+	var queue []todo
+	if r.head != "" {
+		queue = append(queue, todo{sha: r.head})
+	}
+	for _, e := range r.index {
+		queue = append(queue, todo{e.sha, e.path})
+	}
+
+	seen := map[string]bool{}
+	for len(queue) > 0 && len(seen) < maxRepoObjects && ctx.Err() == nil {
+		t := queue[0]
+		queue = queue[1:]
+		if seen[t.sha] {
+			continue
+		}
+		seen[t.sha] = true
+
+		raw, err := r.get(ctx, path.Join("objects", t.sha[:2], t.sha[2:]))
+		if err != nil {
+			continue // packed or gone
+		}
+		kind, body, err := inflateObject(raw)
+		if err != nil {
+			continue
+		}
+
+		switch kind {
+		case "commit":
+			if tree, ok := strings.CutPrefix(string(body), "tree "); ok && len(tree) >= 40 && shaRe.MatchString(tree[:40]) {
+				queue = append(queue, todo{sha: tree[:40]})
+			}
+		case "tree":
+			for _, e := range parseTree(body) {
+				p := path.Join(t.path, e.path)
+				if _, ok := paths[e.sha]; !ok {
+					paths[e.sha] = p
+				}
+				queue = append(queue, todo{e.sha, p})
+			}
+		case "blob":
+			if p := paths[t.sha]; p != "" {
+				if vcsPath(p) {
+					fmt.Printf("[!] not checking out %s from %s\n", p, r.root)
+					continue
+				}
+				if err := writeUnder(dest, p, body); err != nil {
+					fmt.Printf("[!] %v\n", err)
+				}
+			}
+		}
+	}
+}
+
+// vcsPath: p has a .git/.svn/.hg segment (any case, for case-insensitive
+// filesystems)
+func vcsPath(p string) bool {
+	for _, seg := range strings.Split(p, "/") {
+		for _, kind := range vcsDirs {
+			if strings.EqualFold(seg, kind) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inflateObject unpacks a loose object into its type and content
+func inflateObject(raw []byte) (string, []byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return "", nil, err
+	}
+	defer zr.Close()
+	b, err := io.ReadAll(io.LimitReader(zr, maxBody))
+	if err != nil {
+		return "", nil, err
+	}
+	hdr, body, ok := bytes.Cut(b, []byte{0})
+	if !ok {
+		return "", nil, errors.New("bad object header")
+	}
+	kind, _, _ := strings.Cut(string(hdr), " ")
+	return kind, body, nil
+}
+
+// parseTree reads "<mode> <name>\0<20 byte sha>" entries
+func parseTree(b []byte) []indexEntry {
+	var out []indexEntry
+	for len(b) > 0 {
+		sp := bytes.IndexByte(b, ' ')
+		nul := bytes.IndexByte(b, 0)
+		if sp < 0 || nul < sp || nul+21 > len(b) {
+			break
+		}
+		out = append(out, indexEntry{path: string(b[sp+1 : nul]), sha: hex.EncodeToString(b[nul+1 : nul+21])})
+		b = b[nul+21:]
+	}
+	return out
+}
+
+// writeUnder writes body to dir/p, refusing anything that climbs out of dir
+func writeUnder(dir, p string, body []byte) error {
+	clean := filepath.Clean(filepath.FromSlash("/" + p))
+	if clean == string(filepath.Separator) {
+		return fmt.Errorf("refusing to write %q", p)
+	}
+	full := filepath.Join(dir, clean)
+	if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(full, body, 0o644)
+}
@@ -0,0 +1,205 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Vhosts fuzzes the Host header on every web target that's a bare IP (the
+// ones nmap found in CIDRs, mostly). candidates are the in-scope domains
+// plus Enumeration.Vhosts, labels without a dot get every domain appended.
+// whatever answers differently from a random name is a vhost: it becomes a
+// web target (routed to its IP, see ScopeGuard.AddVhost) and a finding.
+func (cfg *Razor) Vhosts(ctx context.Context) ([]Finding, error) {
+	guard, err := cfg.Guard()
+	if err != nil {
+		return nil, err
+	}
+	client, err := cfg.client()
+	if err != nil {
+		return nil, err
+	}
+	bases, err := cfg.WebTargets()
+	if err != nil {
+		return nil, err
+	}
+
+	domains := cfg.scopeDomains()
+	var candidates []string
+	for _, name := range vhostCandidates(domains, cfg.Enumeration.Vhosts) {
+		if !guard.set.exclude.allows(name) { // carve-outs stay carved out, Host header or not
+			candidates = append(candidates, name)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	var findings []Finding
+	var found []string
+	for _, base := range bases {
+		if ctx.Err() != nil {
+			return findings, ctx.Err()
+		}
+		u, err := url.Parse(base)
+		if err != nil || net.ParseIP(u.Hostname()) == nil || !cfg.inFocus(u.Hostname()) {
+			continue
+		}
+
+		calib := calibrateVhosts(ctx, client, u, domains)
+		for _, name := range candidates {
+			if ctx.Err() != nil {
+				return findings, ctx.Err()
+			}
+			resp, body, err := vhostGet(ctx, client, u, name)
+			if err != nil {
+				if errors.Is(err, ErrBudgetExhausted) || errors.Is(err, ErrHostDegraded) {
+					fmt.Printf("[!] %v, stopping vhost fuzzing on %s\n", err, u.Host)
+					break
+				}
+				continue
+			}
+			if resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusMisdirectedRequest {
+				continue
+			}
+			// vhosts of one box often weigh about the same, a new title gives them away
+			title := pageTitle(bytes.ReplaceAll(body, []byte(name), nil))
+			if calib.matchesReflected(resp, body, name) && (title == "" || calib.knowsTitle(title)) {
+				continue
+			}
+
+			vhost := *u
+			vhost.Host = name
+			if p := u.Port(); p != "" {
+				vhost.Host = net.JoinHostPort(name, p)
+			}
+			// explicit targets already resolve on their own, leave them be
+			if _, taken := guard.vhostIP(name); !taken && !cfg.isTargetHost(name) {
+				guard.AddVhost(name, u.Hostname())
+				if cfg.focus != nil {
+					cfg.focus[name] = struct{}{}
+				}
+				found = append(found, vhost.String())
+			}
+
+			findings = append(findings, Finding{
+				Host:     u.Hostname(),
+				Endpoint: vhost.String(),
+				Title:    "Virtual host discovered",
+				Severity: SevInfo,
+				Detail:   fmt.Sprintf("Host: %s on %s answers %d (%q), unlike a random name", name, u.Host, resp.StatusCode, title),
+			})
+		}
+	}
+
+	if err := cfg.AddWebTargets(found); err != nil {
+		return findings, err
+	}
+	return findings, nil
+}
+
+// scopeDomains: every domain named in Scope.Targets, wildcards included
+func (cfg *Razor) scopeDomains() []string {
+	targets, err := cfg.Scope.ParsedTargets()
+	if err != nil {
+		return nil
+	}
+	var domains []string
+	for _, t := range targets {
+		if t.Kind == TargetCIDR || net.ParseIP(t.Host) != nil {
+			continue
+		}
+		if !contains(domains, t.Host) {
+			domains = append(domains, t.Host)
+		}
+	}
+	return domains
+}
+
+func (cfg *Razor) isTargetHost(name string) bool {
+	targets, err := cfg.Scope.ParsedTargets()
+	if err != nil {
+		return false
+	}
+	for _, t := range targets {
+		if t.Kind == TargetHost && strings.EqualFold(t.Host, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// vhostCandidates: the domains themselves, full names from the config and
+// label.domain for every bare label
+func vhostCandidates(domains, names []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	add := func(n string) {
+		n = strings.ToLower(strings.Trim(strings.TrimSpace(n), "."))
+		if n != "" && !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+
+	for _, d := range domains {
+		add(d)
+	}
+	for _, n := range names {
+		if strings.Contains(n, ".") {
+			add(n)
+			continue
+		}
+		for _, d := range domains {
+			add(n + "." + d)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// calibrateVhosts learns what the IP answers for names it doesn't host:
+// no name at all, a random .invalid one and a random one under every domain
+// (catch-all wildcard vhosts)
+func calibrateVhosts(ctx context.Context, client httpDoer, u *url.URL, domains []string) calibration {
+	var c calibration
+
+	names := []string{"", randomToken() + ".invalid"}
+	for _, d := range domains {
+		names = append(names, randomToken()+"."+d)
+	}
+	for _, name := range names {
+		resp, body, err := vhostGet(ctx, client, u, name)
+		if err != nil {
+			continue
+		}
+		c = append(c, fingerprint(resp, body, name))
+	}
+	return c
+}
+
+// vhostGet requests u with Host: name ("" = leave it alone)
+func vhostGet(ctx context.Context, client httpDoer, u *url.URL, name string) (*http.Response, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	if name != "" {
+		req.Host = name
+		if p := u.Port(); p != "" {
+			req.Host = net.JoinHostPort(name, p)
+		}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	return resp, readBody(resp), nil
+}
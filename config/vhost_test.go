@@ -0,0 +1,138 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestVhosts(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.Host)
+		if host != "dev.corp.example" {
+			fmt.Fprintf(w, "<title>default</title>welcome to %s", host) // catch-all
+			return
+		}
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, "<title>dev portal</title>")
+		case "/admin":
+			fmt.Fprint(w, "dev admin")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	port := srv.URL[strings.LastIndex(srv.URL, ":")+1:]
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL, "*.corp.example"}
+	rz.Enumeration.Vhosts = []string{"dev", "www", "intranet.corp.example"}
+	rz.HTTP = srv.Client()
+
+	findings, err := rz.Vhosts(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	vhost := "http://dev.corp.example:" + port + "/"
+	if len(findings) != 1 || findings[0].Endpoint != vhost {
+		t.Fatalf("got %#v", findings)
+	}
+
+	targets, _ := rz.WebTargets()
+	if targets[len(targets)-1] != vhost {
+		t.Fatalf("vhost not added to web targets: %v", targets)
+	}
+
+	// the name doesn't resolve anywhere, requests must still reach the IP
+	out, err := rz.Enum(context.Background(), []string{"admin"})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(out) != 1 || out[0].Endpoint != vhost+"admin" {
+		t.Fatalf("got %#v", out)
+	}
+}
+
+func TestVhosts_RespectsExclusions(t *testing.T) {
+	var seen sync.Map
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.Host)
+		seen.Store(host, true)
+		if host == "payment.example.com" {
+			fmt.Fprint(w, "<title>payments</title>")
+			return
+		}
+		fmt.Fprint(w, "<title>default</title>")
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL, "example.com"}
+	rz.Scope.ExcludeTargets = []string{"payment.example.com"}
+	rz.Enumeration.Vhosts = []string{"payment"}
+	rz.HTTP = srv.Client()
+
+	findings, err := rz.Vhosts(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(findings) != 0 {
+		t.Fatalf("got %#v", findings)
+	}
+	if _, ok := seen.Load("payment.example.com"); ok {
+		t.Fatalf("excluded name sent as Host header")
+	}
+
+	// and nothing else gets to either
+	guard, _ := rz.Guard()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+	req.Host = "payment.example.com"
+	if _, err := guard.Do(req); !errors.Is(err, config.ErrOutOfScope) {
+		t.Fatalf("got %v, wanted ErrOutOfScope", err)
+	}
+}
+
+func TestVhosts_Seed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, _ := net.SplitHostPort(r.Host)
+		if host != "dev.example.com" {
+			fmt.Fprint(w, "<title>default</title>")
+			return
+		}
+		switch r.URL.Path {
+		case "/":
+			fmt.Fprint(w, "<title>dev</title>")
+		case "/robots.txt":
+			fmt.Fprint(w, "User-agent: *\nDisallow: /secret/\n")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL} // IP only, the name comes from enum.vhosts
+	rz.Enumeration.Vhosts = []string{"dev.example.com"}
+	rz.HTTP = srv.Client()
+
+	if f, err := rz.Vhosts(context.Background()); err != nil || len(f) != 1 {
+		t.Fatalf("vhost setup: %v %#v", err, f)
+	}
+	findings, err := rz.Seed(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(findings) != 1 || !strings.HasSuffix(findings[0].Endpoint, "/secret/") ||
+		!strings.Contains(findings[0].Endpoint, "dev.example.com") {
+		t.Fatalf("got %#v", findings)
+	}
+}
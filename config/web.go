@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Ullaakut/nmap/v3"
+)
+
+// ports we call web when service detection came back empty-handed
+var webPorts = map[uint16]string{
+	80:   "http",
+	443:  "https",
+	8000: "http",
+	8008: "http",
+	8080: "http",
+	8443: "https",
+	8888: "http",
+}
+
+// WebServices turns every open HTTP(S) port in run into a base URL. the
+// name a host was scanned under wins over its IP, so vhosts keep working.
+func WebServices(run *nmap.Run) []string {
+	if run == nil {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var urls []string
+	for _, host := range run.Hosts {
+		name := hostName(host)
+		if name == "" {
+			continue
+		}
+
+		for _, port := range host.Ports {
+			if port.Status() != nmap.Open || port.Protocol != "tcp" {
+				continue
+			}
+			scheme := webScheme(port)
+			if scheme == "" {
+				continue
+			}
+
+			u := baseURL(scheme, name, port.ID)
+			if !seen[u] {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// hostName: the name we handed nmap, otherwise its first IP
+func hostName(h nmap.Host) string {
+	for _, hn := range h.Hostnames {
+		if hn.Type == "user" {
+			return strings.ToLower(hn.Name)
+		}
+	}
+	for _, a := range h.Addresses {
+		if a.AddrType == "ipv4" || a.AddrType == "ipv6" {
+			return a.Addr
+		}
+	}
+	return ""
+}
+
+// webScheme says whether port speaks http or https ("" = neither)
+func webScheme(p nmap.Port) string {
+	name := strings.ToLower(p.Service.Name)
+	switch {
+	case strings.Contains(name, "http"): // http, https, http-proxy, http-alt, ssl/http...
+	case name == "" || name == "unknown" || name == "tcpwrapped":
+		return webPorts[p.ID]
+	default:
+		return ""
+	}
+
+	if p.Service.Tunnel == "ssl" || strings.HasPrefix(name, "https") || strings.HasPrefix(name, "ssl/") {
+		return "https"
+	}
+	return "http"
+}
+
+func baseURL(scheme, host string, port uint16) string {
+	if (scheme == "http" && port == 80) || (scheme == "https" && port == 443) {
+		if strings.Contains(host, ":") {
+			host = "[" + host + "]" // IPv6
+		}
+		return fmt.Sprintf("%s://%s/", scheme, host)
+	}
+	return fmt.Sprintf("%s://%s/", scheme, net.JoinHostPort(host, strconv.Itoa(int(port))))
+}
+
+// AddWebTargets feeds base URLs (see WebServices) to Enum and the intrusive
+// modules. anything out of scope is dropped here, quietly.
+func (cfg *Razor) AddWebTargets(urls []string) error {
+	guard, err := cfg.Guard()
+	if err != nil {
+		return err
+	}
+
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil {
+			continue
+		}
+		if ok, _ := guard.allows(u); !ok {
+			continue
+		}
+		if !contains(cfg.web, raw) {
+			cfg.web = append(cfg.web, raw)
+		}
+	}
+	return nil
+}
+
+// WebTargets are the base URLs from Scope.Targets plus the ones nmap found
+func (cfg *Razor) WebTargets() ([]string, error) {
+	bases, err := cfg.Scope.BaseURLs()
+	if err != nil {
+		return nil, err
+	}
+	for _, u := range cfg.web {
+		if !contains(bases, u) {
+			bases = append(bases, u)
+		}
+	}
+	return bases, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
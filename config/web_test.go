@@ -0,0 +1,78 @@
+package config_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/Ullaakut/nmap/v3"
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func webPort(id uint16, service, tunnel string) nmap.Port {
+	return nmap.Port{
+		ID:       id,
+		Protocol: "tcp",
+		State:    nmap.State{State: "open"},
+		Service:  nmap.Service{Name: service, Tunnel: tunnel},
+	}
+}
+
+func TestWebServices(t *testing.T) {
+	run := &nmap.Run{Hosts: []nmap.Host{
+		{
+			Addresses: []nmap.Address{{Addr: "10.0.0.5", AddrType: "ipv4"}},
+			Hostnames: []nmap.Hostname{{Name: "App.Example.com", Type: "user"}},
+			Ports: []nmap.Port{
+				webPort(22, "ssh", ""),
+				webPort(80, "http", ""),
+				webPort(443, "http", "ssl"),
+				webPort(8080, "http-proxy", ""),
+				webPort(9443, "https-alt", ""),
+			},
+		},
+		{
+			Addresses: []nmap.Address{{Addr: "10.0.0.9", AddrType: "ipv4"}},
+			Ports: []nmap.Port{
+				webPort(8443, "", ""), // no service detection
+				webPort(3306, "mysql", ""),
+				{ID: 8000, Protocol: "tcp", State: nmap.State{State: "closed"}, Service: nmap.Service{Name: "http"}},
+			},
+		},
+	}}
+
+	want := []string{
+		"http://app.example.com/",
+		"http://app.example.com:8080/",
+		"https://10.0.0.9:8443/",
+		"https://app.example.com/",
+		"https://app.example.com:9443/",
+	}
+	if got := config.WebServices(run); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestAddWebTargets(t *testing.T) {
+	var rz config.Razor
+	rz.Scope.Targets = []string{"10.0.0.0/24", "app.example.com"}
+	rz.Scope.ExcludeTargets = []string{"10.0.0.9"}
+
+	err := rz.AddWebTargets([]string{
+		"http://10.0.0.5:8080/",
+		"https://10.0.0.9:8443/", // excluded
+		"http://other.example.com/",
+		"https://app.example.com/", // already there
+	})
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+
+	got, err := rz.WebTargets()
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := []string{"http://app.example.com/", "https://app.example.com/", "http://10.0.0.5:8080/"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
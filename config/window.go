@@ -0,0 +1,129 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// ErrOutsideWindow means the time window is closed and won't open again
+var ErrOutsideWindow = errors.New("outside of the allowed time window")
+
+const day = 24 * time.Hour
+
+// Window enforces Scope.TimeWindow. no window = always open.
+type Window struct {
+	tw TimeWindow
+}
+
+func NewWindow(tw TimeWindow) *Window {
+	return &Window{tw: tw}
+}
+
+func (cfg *Razor) Window() *Window {
+	return NewWindow(cfg.Scope.TimeWindow)
+}
+
+// Next returns the window containing t, or the first one after it.
+// ok is false when there is no window left. zero start/end = unbounded.
+func (w *Window) Next(t time.Time) (start, end time.Time, ok bool) {
+	s, e := w.tw.Start.Time, w.tw.End.Time
+	if s.IsZero() || e.IsZero() {
+		return time.Time{}, time.Time{}, true
+	}
+
+	if w.tw.Repeat != "daily" || t.Before(s) {
+		return s, e, t.Before(e)
+	}
+
+	// daily: same slot every day, starting from the configured one
+	d := e.Sub(s)
+	cur := s.Add(t.Sub(s) / day * day)
+	if t.Before(cur.Add(d)) {
+		return cur, cur.Add(d), true
+	}
+	return cur.Add(day), cur.Add(day + d), true
+}
+
+func (w *Window) Open(t time.Time) bool {
+	s, _, ok := w.Next(t)
+	return ok && !t.Before(s)
+}
+
+// Closes returns when the window open at t ends (zero = never)
+func (w *Window) Closes(t time.Time) time.Time {
+	_, e, _ := w.Next(t)
+	return e
+}
+
+// Wait blocks until the window is open
+func (w *Window) Wait(ctx context.Context) error {
+	for {
+		now := time.Now()
+		s, _, ok := w.Next(now)
+		if !ok {
+			return ErrOutsideWindow
+		}
+		if !now.Before(s) {
+			return nil
+		}
+
+		timer := time.NewTimer(s.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// supervise pauses the tool while the window is closed and resumes it when
+// the next one opens. returns once done is closed.
+func (w *Window) supervise(done <-chan struct{}, ps *pauser) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-done
+		cancel()
+	}()
+
+	const reason = "time window closed"
+	for {
+		closes := w.Closes(time.Now())
+		if closes.IsZero() {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(closes))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		ps.pause(reason)
+		if err := w.Wait(ctx); err != nil {
+			if !errors.Is(err, context.Canceled) {
+				ps.kill("no time window left")
+			}
+			return
+		}
+		ps.resume(reason)
+	}
+}
+
+// windowDoer holds every request until the window is open
+type windowDoer struct {
+	win  *Window
+	next httpDoer
+}
+
+func (d *windowDoer) Do(req *http.Request) (*http.Response, error) {
+	if err := d.win.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return d.next.Do(req)
+}
@@ -0,0 +1,84 @@
+package config_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+func TestWindow_Daily(t *testing.T) {
+	var tw config.TimeWindow
+	tw.Start.Time = time.Date(2025, 9, 1, 19, 0, 0, 0, time.UTC)
+	tw.End.Time = time.Date(2025, 9, 2, 6, 0, 0, 0, time.UTC)
+	tw.Repeat = "daily"
+	w := config.NewWindow(tw)
+
+	type tests struct {
+		at   time.Time
+		open bool
+		next time.Time
+	}
+	testCases := []tests{
+		{time.Date(2025, 9, 1, 12, 0, 0, 0, time.UTC), false, tw.Start.Time},
+		{time.Date(2025, 9, 1, 20, 0, 0, 0, time.UTC), true, tw.Start.Time},
+		{time.Date(2025, 9, 5, 3, 0, 0, 0, time.UTC), true, time.Date(2025, 9, 4, 19, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 9, 5, 7, 0, 0, 0, time.UTC), false, time.Date(2025, 9, 5, 19, 0, 0, 0, time.UTC)},
+	}
+
+	for _, test := range testCases {
+		start, _, ok := w.Next(test.at)
+		if !ok || !start.Equal(test.next) || w.Open(test.at) != test.open {
+			t.Errorf("%v: got start %v open %v", test.at, start, w.Open(test.at))
+		}
+	}
+}
+
+func TestWindow_OneShotOver(t *testing.T) {
+	var tw config.TimeWindow
+	tw.Start.Time = time.Date(2025, 9, 1, 19, 0, 0, 0, time.UTC)
+	tw.End.Time = time.Date(2025, 9, 2, 6, 0, 0, 0, time.UTC)
+	w := config.NewWindow(tw)
+
+	if _, _, ok := w.Next(time.Date(2025, 9, 3, 0, 0, 0, 0, time.UTC)); ok {
+		t.Errorf("window should be over")
+	}
+	if !config.NewWindow(config.TimeWindow{}).Open(time.Now()) {
+		t.Errorf("empty window should always be open")
+	}
+}
+
+func TestWindow_OverStopsModules(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	var rz config.Razor
+	rz.Scope.Targets = []string{srv.URL}
+	rz.Scope.TimeWindow.Start.Time = time.Now().Add(-2 * time.Hour)
+	rz.Scope.TimeWindow.End.Time = time.Now().Add(-time.Hour)
+	rz.HTTP = srv.Client()
+
+	out, err := rz.Enum(context.Background(), []string{"admin", "login", "backup"})
+	if !errors.Is(err, config.ErrOutsideWindow) {
+		t.Fatalf("enum: got %v, wanted ErrOutsideWindow", err)
+	}
+	for _, r := range out {
+		if r.Failed() {
+			t.Errorf("closed window recorded as failure: %#v", r)
+		}
+	}
+
+	if _, err := rz.Seed(context.Background()); !errors.Is(err, config.ErrOutsideWindow) {
+		t.Errorf("seed: got %v, wanted ErrOutsideWindow", err)
+	}
+	res := []config.DirEnumRes{{Endpoint: srv.URL + "/admin", StatusCode: 200}}
+	if _, err := rz.Methods(context.Background(), res); !errors.Is(err, config.ErrOutsideWindow) {
+		t.Errorf("methods: got %v, wanted ErrOutsideWindow", err)
+	}
+}
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ZeroPvlse/razor/defaults"
+)
+
+// Wordlists says where Enum's words come from. everything gets merged in
+// order (builtin, files, inline), deduped and normalised. all empty =
+// builtin "common".
+type Wordlists struct {
+	Builtin []string `yaml:"builtin"` // names from defaults.Wordlists
+	Files   []string `yaml:"files"`   // SecLists style, one per line, '#' comments. relative to the config file
+	Inline  []string `yaml:"inline"`
+}
+
+// Resolve builds the final wordlist. dir is where relative files live.
+func (w Wordlists) Resolve(dir string) ([]string, error) {
+	builtin := w.Builtin
+	if len(builtin) == 0 && len(w.Files) == 0 && len(w.Inline) == 0 {
+		builtin = []string{"common"}
+	}
+
+	var words []string
+	for _, name := range builtin {
+		list, ok := defaults.Wordlists[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown builtin wordlist %q", name)
+		}
+		words = append(words, list...)
+	}
+
+	for _, path := range w.Files {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		list, err := readWordlist(path)
+		if err != nil {
+			return nil, err
+		}
+		words = append(words, list...)
+	}
+
+	words = append(words, w.Inline...)
+	return NormalizeWords(words), nil
+}
+
+func readWordlist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("wordlist: %w", err)
+	}
+	defer f.Close()
+
+	var words []string
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		words = append(words, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("wordlist %s: %w", path, err)
+	}
+	return words, nil
+}
+
+// characters that can't go into a request path unescaped
+const invalidPathChars = " \t\"<>\\^`{|}"
+
+// SecLists placeholder for the target's extension ("index.%EXT%"), Enum
+// fills it in with the stack extensions of each base, see withExtensions
+const extPlaceholder = "%EXT%"
+
+// NormalizeWords drops blanks and '#' comments, gives every word exactly
+// one leading '/', percent-escapes words that aren't valid URL paths
+// (spaces, stray '%') and removes duplicates (first one wins). words that
+// are already escaped are left alone, so is %EXT%.
+func NormalizeWords(raw []string) []string {
+	seen := map[string]bool{}
+	words := make([]string, 0, len(raw))
+
+	for _, w := range raw {
+		w = strings.TrimSpace(w)
+		if w == "" || strings.HasPrefix(w, "#") {
+			continue
+		}
+		w = "/" + strings.TrimLeft(w, "/")
+		if !validPath(strings.ReplaceAll(w, extPlaceholder, "")) {
+			w = (&url.URL{Path: w}).EscapedPath()
+			w = strings.ReplaceAll(w, url.PathEscape(extPlaceholder), extPlaceholder)
+		}
+		if w == "/" || seen[w] {
+			continue
+		}
+		seen[w] = true
+		words = append(words, w)
+	}
+	return words
+}
+
+// validPath reports whether p can go into a request as is
+func validPath(p string) bool {
+	_, err := url.PathUnescape(p)
+	return err == nil && !strings.ContainsAny(p, invalidPathChars)
+}
@@ -0,0 +1,59 @@
+package config_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ZeroPvlse/razor/config"
+	"github.com/ZeroPvlse/razor/defaults"
+)
+
+func TestWordlists_Resolve(t *testing.T) {
+	dir := t.TempDir()
+	list := "# SecLists header\n\nadmin\n/backup\n//admin\n  login  \n"
+	if err := os.WriteFile(filepath.Join(dir, "small.txt"), []byte(list), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	w := config.Wordlists{
+		Files:  []string{"small.txt"},
+		Inline: []string{"secret", "/login", "#nope"},
+	}
+	got, err := w.Resolve(dir)
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	want := []string{"/admin", "/backup", "/login", "/secret"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWordlists_Default(t *testing.T) {
+	got, err := config.Wordlists{}.Resolve(".")
+	if err != nil {
+		t.Fatalf("unexpected err: %v", err)
+	}
+	if len(got) == 0 || len(got) > len(defaults.CommonEndpoints) {
+		t.Errorf("expected the common list, got %d words", len(got))
+	}
+}
+
+func TestWordlists_Errors(t *testing.T) {
+	if _, err := (config.Wordlists{Builtin: []string{"nope"}}).Resolve("."); err == nil {
+		t.Error("expected error for unknown builtin")
+	}
+	if _, err := (config.Wordlists{Files: []string{"missing.txt"}}).Resolve(t.TempDir()); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestNormalizeWords_Escapes(t *testing.T) {
+	got := config.NormalizeWords([]string{"index.%EXT%", "my file.%EXT%", "my file", "100%", "%2e%2e/etc", "admin"})
+	want := []string{"/index.%EXT%", "/my%20file.%EXT%", "/my%20file", "/100%25", "/%2e%2e/etc", "/admin"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %q", got)
+	}
+}
@@ -67,6 +67,11 @@ var Rare = []int{
 	1025,  // Ephemeral / old services
 }
 
+// builtin wordlists, referenced by name from the config's wordlists section
+var Wordlists = map[string][]string{
+	"common": CommonEndpoints,
+}
+
 var CommonEndpoints = []string{
 	// auth & User
 	"/login", "/signin", "/auth", "/session",
@@ -117,7 +122,7 @@ var CommonEndpoints = []string{
 	// language specific
 	"/phpinfo.php", "/server.php", "/info.php",
 	"/config.php", "/localsettings.php", "/wp-config.php",
-	"/index.php~", "/index.php.bak", "/index.old.php",
+	"/index.php", "/index.old.php", // index.php.bak & co come from Enum's backup permutations
 
 	// well known
 	"/.well-known/", "/.well-known/security.txt", "/.well-known/change-password",
@@ -130,3 +135,29 @@ var CommonEndpoints = []string{
 	"/keys", "/secret", "/secrets", "/credentials",
 	"/env", "/.env", "/environment", "/config.json",
 }
+
+// Enum tries these on every file it finds, leftovers from editors and admins
+var BackupSuffixes = []string{".bak", ".old", "~", ".swp", ".zip", ".tar.gz", ".orig"}
+
+// extensions worth trying per stack, keyed by a lowercase word of a
+// notes.stack_hints entry ("Apache Tomcat/9" -> tomcat)
+var StackExtensions = map[string][]string{
+	"php":        {".php"},
+	"wordpress":  {".php"},
+	"drupal":     {".php"},
+	"laravel":    {".php"},
+	"asp":        {".asp", ".aspx", ".ashx", ".asmx"},
+	".net":       {".aspx", ".ashx", ".asmx"},
+	"iis":        {".asp", ".aspx"},
+	"java":       {".jsp", ".do", ".action"},
+	"tomcat":     {".jsp", ".do"},
+	"spring":     {".jsp", ".do"},
+	"struts":     {".do", ".action"},
+	"coldfusion": {".cfm", ".cfc"},
+	"perl":       {".pl", ".cgi"},
+	"cgi":        {".cgi"},
+	"python":     {".py"},
+	"ruby":       {".rb"},
+	"node":       {".js", ".json"},
+	"node.js":    {".js", ".json"},
+}
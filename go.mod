@@ -3,8 +3,9 @@ module github.com/ZeroPvlse/razor
 go 1.24.4
 
 require (
-	github.com/Ullaakut/nmap/v3 v3.0.6 // indirect
-	github.com/akamensky/argparse v1.4.0 // indirect
-	golang.org/x/sync v0.1.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
+	github.com/Ullaakut/nmap/v3 v3.0.6
+	golang.org/x/sync v0.1.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require github.com/akamensky/argparse v1.4.0 // indirect
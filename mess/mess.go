@@ -31,10 +31,15 @@ scope:
   targets: []                       # EXACT stuff we're allowed to poke: domains/IPs/CIDRs. if it’s not here, we don’t touch it.
   include_ports: []                 # only list ports if the client is picky. blank = safe defaults; we won't scan half the internet.
   max_hosts: 0                      # seatbelt for huge scopes. 0 = no cap. turn it up if time is tight and scope is thicc. it basically means from whole scope how many findings are the "key ones". it will automatically pick the most crucial ones UP TO max_hosts value.
-  allow_intrusive: false            # leave false unless client said "go harder." true = spicier checks, more noise, more sideeye. (XSSscannig, sqli shit like that)
+  allow_intrusive: false            # leave false unless client said "go harder." true = spicier checks, more noise, more sideeye. (XSSscannig, sqli, PUT/DELETE probes shit like that)
   time_window:                      # optional 'do it off-hours' window (UTC). leave blank if nobody cares.
     start: ""                       # e.g. "2025-09-01T19:00:00Z" - or empty if no window.
     end: ""                         # e.g. "2025-09-02T06:00:00Z" - or empty, same deal.
+    repeat: ""                      # "daily" = same slot every day (start/end less than 24h apart), blank = one shot.
+    wait: false                     # true = sit and wait for the window to open. false = refuse to start outside it.
+  exclude_targets: []               # carve-outs from targets: "the /24 except the payment box". domains/IPs/CIDRs.
+  exclude_ports: []                 # ports we never touch, even if include_ports has them.
+  exclude_paths: []                 # web paths that are off limits e.g. "/checkout". covers everything below it too.
 
 limits:
   rps_per_host: 2                   # requests/sec per host. chill setting so WAFs don't start drama.
@@ -43,6 +48,22 @@ limits:
   connect_timeout_s: 5              # if we can't connect by now, we move on. life's short.
   request_timeout_s: 10             # don't wait forever for sleepy servers.
   retries: 2                        # how many second chances we give flaky endpoints before we say "nah."
+  scan_timeout_s: 0                 # give up on one nmap run after this long. 0 = no cap, the time window and ctrl-c still stop it.
+  canary_interval_s: 15             # how often we poke each host with a harmless GET / to see if it's still ok. -1 = off.
+  canary_max_latency_ms: 3000       # canary slower than this = host is struggling.
+  canary_max_error_rate: 0.5        # share of bad canary probes (errors, 5xx, slow) before we back off the host.
+  canary_action: "pause"            # "pause" = wait till it recovers, "abort" = leave that host alone for good. either way it's an incident.
+
+enum:
+  recursion_depth: 0                # dig into dirs we find (/api/, /admin) this many levels deep. 0 = just the top level. budget still applies.
+  recursion_wordlist: []            # words for the deeper levels. blank = same list as the top.
+  redirects: "none"                 # none = just note where 3xx points. same-host = follow on the same host. follow = follow anywhere in scope.
+  vhosts: []                        # Host names to try on bare IPs, e.g. "dev" (-> dev.<each target domain>) or "intranet.corp.local".
+
+wordlists:                          # what enum throws at every target. all blank = builtin "common" list.
+  builtin: []                       # named lists shipped with razor: common.
+  files: []                         # SecLists style files, one path per line, '#' = comment. relative to this config.
+  inline: []                        # quick extras like "/secret-admin". dupes and leading slashes get cleaned up.
 
 report:
   deliverables: []                  # what to spit out. pick from: pdf_exec, html_tech, json_findings. blank = reasonable defaults.
@@ -52,7 +73,7 @@ report:
   out_dir: ""                       # where to dump files. blank = default folder; we keep it tidy.
 
 notes:
-  stack_hints: []                   # client hints like "WordPress", "Nginx", "AWS". guesses welcome; helps aim checks.
+  stack_hints: []                   # client hints like "WordPress", "Nginx", "AWS". guesses welcome; helps aim checks. razor adds whatever it fingerprints; drives which extensions/words enum tries (PHP -> .php).
   contacts: []                      # who we ping if something looks spicy. emails or chat handles. no ghosting.
   tags: []                          # labels for later: "prod", "EU", "quarterly", "pls-don't-break".`)
 
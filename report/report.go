@@ -0,0 +1,130 @@
+// turns whatever a run found into the deliverables picked in the config
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ZeroPvlse/razor/config"
+)
+
+// Run is everything that ends up in the deliverables
+type Run struct {
+	Name      string               `json:"name"`
+	Client    string               `json:"client"`
+	Generated time.Time            `json:"generated"`
+	KeyHosts  []config.HostScore   `json:"key_hosts"`
+	Hosts     []config.HostScore   `json:"hosts"`
+	Tech      []config.Tech        `json:"tech,omitempty"`
+	Endpoints []config.DirEnumRes  `json:"endpoints"`
+	Findings  []config.Finding     `json:"findings"`
+	Blocked   []string             `json:"blocked_out_of_scope,omitempty"`
+	Budget    []config.BudgetUsage `json:"budget"`
+	Events    []config.Event       `json:"events"`
+}
+
+// Write dumps run into dir, one file per deliverable. returns the files written.
+func Write(dir string, deliverables []string, run *Run) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	for _, d := range deliverables {
+		var (
+			path string
+			err  error
+		)
+		switch d {
+		case "json_findings":
+			path = filepath.Join(dir, "findings.json")
+			err = writeJSON(path, run)
+		case "html_tech":
+			path = filepath.Join(dir, "report.html")
+			err = writeHTML(path, run)
+		default:
+			fmt.Printf("[!] %s isn't implemented yet, skipping\n", d)
+			continue
+		}
+		if err != nil {
+			return written, fmt.Errorf("writing %s: %w", d, err)
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
+
+func writeJSON(path string, run *Run) error {
+	b, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+func writeHTML(path string, run *Run) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return techTmpl.Execute(f, run)
+}
+
+var techTmpl = template.Must(template.New("tech").Parse(`<!doctype html>
+<html>
+<head><meta charset="utf-8"><title>{{.Name}} - {{.Client}}</title></head>
+<body>
+<h1>{{.Name}} ({{.Client}})</h1>
+<p>generated {{.Generated.UTC.Format "2006-01-02 15:04 MST"}}</p>
+
+<h2>Key hosts</h2>
+<table border="1">
+<tr><th>host</th><th>score</th><th>open ports</th><th>services</th><th>endpoints</th><th>findings</th></tr>
+{{range .KeyHosts}}<tr><td>{{.Host}} {{range .Names}}{{.}} {{end}}</td><td>{{.Score}}</td><td>{{.OpenPorts}}</td><td>{{.Services}}</td><td>{{.Endpoints}}</td><td>{{.Findings}}</td></tr>
+{{end}}</table>
+
+{{if .Tech}}
+<h2>Technologies</h2>
+<table border="1">
+<tr><th>target</th><th>technology</th><th>version</th><th>evidence</th></tr>
+{{range .Tech}}<tr><td>{{.URL}}</td><td>{{.Name}}</td><td>{{.Version}}</td><td>{{range .Evidence}}{{.}}<br>{{end}}</td></tr>
+{{end}}</table>
+{{end}}
+
+<h2>Findings</h2>
+<table border="1">
+<tr><th>severity</th><th>host</th><th>title</th><th>endpoint</th><th>detail</th></tr>
+{{range .Findings}}<tr><td>{{.Severity}}</td><td>{{.Host}}</td><td>{{.Title}}</td><td>{{.Endpoint}}</td><td>{{.Detail}}</td></tr>
+{{end}}</table>
+
+<h2>Endpoints</h2>
+<table border="1">
+<tr><th>status</th><th>endpoint</th><th>length</th><th>type</th><th>title</th><th>location</th><th>headers</th><th>time</th><th>error</th></tr>
+{{range .Endpoints}}<tr><td>{{.StatusCode}}</td><td>{{.Endpoint}}</td><td>{{.ContentLength}}</td><td>{{.ContentType}}</td><td>{{.Title}}</td><td>{{.Location}}</td><td>{{range $k, $v := .Headers}}{{$k}}: {{$v}}<br>{{end}}</td><td>{{.ResponseTime}}</td><td>{{.Error}}</td></tr>
+{{end}}</table>
+<h2>Request budget</h2>
+<table border="1">
+<tr><th>host</th><th>used</th><th>estimated (external tools)</th><th>limit</th></tr>
+{{range .Budget}}<tr><td>{{.Host}}</td><td>{{.Used}}</td><td>{{.Estimated}}</td><td>{{.Limit}}</td></tr>
+{{end}}</table>
+{{if .Events}}
+<h2>Run log</h2>
+<table border="1">
+<tr><th>time</th><th>host</th><th>event</th><th>detail</th></tr>
+{{range .Events}}<tr><td>{{.Time.UTC.Format "15:04:05"}}</td><td>{{.Host}}</td><td>{{.Kind}}</td><td>{{.Detail}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .Blocked}}
+<h2>Blocked out-of-scope requests</h2>
+<ul>{{range .Blocked}}<li>{{.}}</li>{{end}}</ul>
+{{end}}
+</body>
+</html>
+`))